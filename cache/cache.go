@@ -0,0 +1,361 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/home"
+)
+
+// Cache stores JSON values under ~/.cache/sesh/<source>/<scope>.json. Writes
+// replace the file atomically and are serialized with a file lock, so
+// concurrent sesh processes never read a partial file.
+type Cache interface {
+	// Get decodes the cached value into value, expired values are returned too
+	// so callers can serve them while refreshing
+	Get(source, scope string, value any) (Entry, bool)
+	// Set caches value for ttl
+	Set(source, scope string, value any, ttl time.Duration) error
+	// Entries lists the cached entries of a source, or of all sources
+	Entries(source string) ([]Entry, error)
+	// Clear removes the cached entries of a source, or of all sources
+	Clear(source string) error
+	// Dir is the directory of a source, or the cache root
+	Dir(source string) string
+	// Flush saves the hits counted by this process and forgets the hits of
+	// expired entries
+	Flush() error
+}
+
+// Entry describes a cached value
+type Entry struct {
+	Source    string    `json:"source"`
+	Scope     string    `json:"scope"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	CachedAt  time.Time `json:"cached_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Hits      int       `json:"hits"`
+}
+
+// Expired reports whether the entry outlived its ttl
+func (e Entry) Expired() bool {
+	return time.Now().After(e.ExpiresAt)
+}
+
+// file is the format of a cache file
+type file struct {
+	Scope     string          `json:"scope"`
+	CachedAt  time.Time       `json:"cached_at"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+const hitsFile = "hits.json"
+
+// hitCount is an entry of the hits file, the expiry of the entry lets hits be
+// forgotten without reading the entry
+type hitCount struct {
+	Hits      int       `json:"hits"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+var (
+	safeName  = regexp.MustCompile(`^[A-Za-z0-9@._-]+$`)
+	safeScope = regexp.MustCompile(`^[A-Za-z0-9@._-]+(/[A-Za-z0-9@._-]+)*$`)
+)
+
+type RealCache struct {
+	home home.Home
+	// hits are counted in memory and saved once by Flush, so reads don't
+	// have to lock and rewrite the hits file
+	mu      sync.Mutex
+	saved   map[string]hitCount // the hits file, read on the first hit
+	pending map[string]hitCount
+}
+
+func NewCache(home home.Home) Cache {
+	return &RealCache{home: home, pending: make(map[string]hitCount)}
+}
+
+func (c *RealCache) Get(source, scope string, value any) (Entry, bool) {
+	path, err := c.path(source, scope)
+	if err != nil {
+		slog.Warn("Invalid cache key", "source", source, "scope", scope, "error", err)
+		return Entry{}, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("Failed to read cache file", "path", path, "error", err)
+		}
+		return Entry{}, false
+	}
+
+	var cached file
+	if err := json.Unmarshal(data, &cached); err != nil {
+		slog.Warn("Failed to unmarshal cache", "path", path, "error", err)
+		return Entry{}, false
+	}
+	if err := json.Unmarshal(cached.Value, value); err != nil {
+		slog.Warn("Failed to unmarshal cached value", "path", path, "error", err)
+		return Entry{}, false
+	}
+
+	hits := c.hit(source+"/"+scope, cached.ExpiresAt)
+	slog.Debug("Cache hit", "source", source, "scope", scope, "expires_at", cached.ExpiresAt)
+	return Entry{
+		Source:    source,
+		Scope:     scope,
+		Path:      path,
+		Size:      int64(len(data)),
+		CachedAt:  cached.CachedAt,
+		ExpiresAt: cached.ExpiresAt,
+		Hits:      hits,
+	}, true
+}
+
+func (c *RealCache) Set(source, scope string, value any, ttl time.Duration) error {
+	path, err := c.path(source, scope)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache value: %w", err)
+	}
+	now := time.Now()
+	data, err := json.MarshalIndent(file{Scope: scope, CachedAt: now, ExpiresAt: now.Add(ttl), Value: raw}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	unlock, err := lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := writeAtomic(path, data); err != nil {
+		return err
+	}
+	slog.Debug("Cache updated", "source", source, "scope", scope, "expires_at", now.Add(ttl))
+	return nil
+}
+
+func (c *RealCache) Entries(source string) ([]Entry, error) {
+	root := c.Dir(source)
+	hits := c.readHits()
+	c.mu.Lock()
+	for key, pending := range c.pending {
+		hits[key] = hitCount{Hits: hits[key].Hits + pending.Hits, ExpiresAt: pending.ExpiresAt}
+	}
+	c.mu.Unlock()
+	entries := []Entry{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" || path == filepath.Join(c.Dir(""), hitsFile) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var cached file
+		if err := json.Unmarshal(data, &cached); err != nil || cached.Scope == "" {
+			return nil // not written by the cache
+		}
+
+		rel, _ := filepath.Rel(c.Dir(""), path)
+		entrySource, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+		entries = append(entries, Entry{
+			Source:    entrySource,
+			Scope:     cached.Scope,
+			Path:      path,
+			Size:      int64(len(data)),
+			CachedAt:  cached.CachedAt,
+			ExpiresAt: cached.ExpiresAt,
+			Hits:      hits[entrySource+"/"+cached.Scope].Hits,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(a.Source+"/"+a.Scope, b.Source+"/"+b.Scope)
+	})
+	return entries, nil
+}
+
+func (c *RealCache) Clear(source string) error {
+	entries, err := c.Entries(source)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.Remove(entry.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove cache file %s: %w", entry.Path, err)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.saved = nil
+	forget := func(hits map[string]hitCount) {
+		for key := range hits {
+			if source == "" || strings.HasPrefix(key, source+"/") {
+				delete(hits, key)
+			}
+		}
+	}
+	forget(c.pending)
+	return c.updateHits(forget)
+}
+
+func (c *RealCache) Dir(source string) string {
+	root, err := c.home.ExpandHome("~/.cache/sesh")
+	if err != nil {
+		slog.Error("Failed to get home directory", "error", err)
+		return ""
+	}
+	if source == "" {
+		return root
+	}
+	return filepath.Join(root, source)
+}
+
+// path is the file of a scope, slashes in the scope are subdirectories. Other
+// scopes, like paths or templates, are stored under their hash.
+func (c *RealCache) path(source, scope string) (string, error) {
+	if !safeName.MatchString(source) {
+		return "", fmt.Errorf("invalid cache source %q", source)
+	}
+	if scope == "" {
+		return "", fmt.Errorf("empty cache scope")
+	}
+	name := scope
+	if len(scope) > 200 || !safeScope.MatchString(scope) || slices.ContainsFunc(strings.Split(scope, "/"), func(segment string) bool {
+		return segment == "." || segment == ".."
+	}) {
+		sum := sha256.Sum256([]byte(scope))
+		name = "_" + hex.EncodeToString(sum[:8])
+	}
+	return filepath.Join(c.Dir(source), filepath.FromSlash(name)+".json"), nil
+}
+
+// hit counts a read of the key and returns its hits
+func (c *RealCache) hit(key string, expiresAt time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.saved == nil {
+		c.saved = c.readHits()
+	}
+	pending := c.pending[key]
+	pending.Hits++
+	pending.ExpiresAt = expiresAt
+	c.pending[key] = pending
+	return c.saved[key].Hits + pending.Hits
+}
+
+func (c *RealCache) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) == 0 {
+		return nil
+	}
+	now := time.Now()
+	err := c.updateHits(func(hits map[string]hitCount) {
+		for key, pending := range c.pending {
+			hits[key] = hitCount{Hits: hits[key].Hits + pending.Hits, ExpiresAt: pending.ExpiresAt}
+		}
+		for key, count := range hits {
+			if now.After(count.ExpiresAt) {
+				delete(hits, key)
+			}
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save cache hits: %w", err)
+	}
+	c.pending = make(map[string]hitCount)
+	c.saved = nil
+	return nil
+}
+
+// readHits reads the hits file, the hits of an older format are dropped
+func (c *RealCache) readHits() map[string]hitCount {
+	hits := make(map[string]hitCount)
+	data, err := os.ReadFile(filepath.Join(c.Dir(""), hitsFile))
+	if err != nil {
+		return hits
+	}
+	if err := json.Unmarshal(data, &hits); err != nil {
+		return make(map[string]hitCount)
+	}
+	return hits
+}
+
+func (c *RealCache) updateHits(update func(map[string]hitCount)) error {
+	path := filepath.Join(c.Dir(""), hitsFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	unlock, err := lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	hits := c.readHits()
+	update(hits)
+	data, err := json.Marshal(hits)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, data)
+}
+
+// writeAtomic writes to a temp file and renames it into place, so readers
+// see either the old or the new file
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace cache file %s: %w", path, err)
+	}
+	return nil
+}
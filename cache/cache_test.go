@@ -0,0 +1,168 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCache(t *testing.T) Cache {
+	t.Setenv("HOME", t.TempDir())
+	return NewCache(home.NewHome(oswrap.NewOs()))
+}
+
+func TestGetSet(t *testing.T) {
+	t.Run("should return the cached value with its metadata", func(t *testing.T) {
+		cache := newTestCache(t)
+		assert.NoError(t, cache.Set("github", "acme", []string{"api", "web"}, time.Hour))
+
+		var value []string
+		entry, found := cache.Get("github", "acme", &value)
+		assert.True(t, found)
+		assert.Equal(t, []string{"api", "web"}, value)
+		assert.Equal(t, "github", entry.Source)
+		assert.Equal(t, "acme", entry.Scope)
+		assert.Equal(t, filepath.Join(cache.Dir("github"), "acme.json"), entry.Path)
+		assert.False(t, entry.Expired())
+		assert.Equal(t, 1, entry.Hits)
+	})
+
+	t.Run("should return expired values", func(t *testing.T) {
+		cache := newTestCache(t)
+		assert.NoError(t, cache.Set("github", "acme", "stale", 0))
+
+		var value string
+		entry, found := cache.Get("github", "acme", &value)
+		assert.True(t, found)
+		assert.True(t, entry.Expired())
+		assert.Equal(t, "stale", value)
+	})
+
+	t.Run("should miss unknown scopes", func(t *testing.T) {
+		cache := newTestCache(t)
+		var value string
+		_, found := cache.Get("github", "acme", &value)
+		assert.False(t, found)
+	})
+
+	t.Run("should hash scopes that aren't safe file names", func(t *testing.T) {
+		cache := newTestCache(t)
+		assert.NoError(t, cache.Set("preview", "/home/test/../etc", "output", time.Hour))
+
+		var value string
+		entry, found := cache.Get("preview", "/home/test/../etc", &value)
+		assert.True(t, found)
+		assert.Equal(t, "output", value)
+		assert.Equal(t, cache.Dir("preview"), filepath.Dir(entry.Path))
+	})
+
+	t.Run("should reject unsafe sources", func(t *testing.T) {
+		cache := newTestCache(t)
+		assert.Error(t, cache.Set("../github", "acme", "value", time.Hour))
+	})
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	cache := newTestCache(t)
+	value := make([]int, 1000)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, cache.Set("github", "acme", value, time.Hour))
+		}()
+		go func() {
+			defer wg.Done()
+			var cached []int
+			if _, found := cache.Get("github", "acme", &cached); found {
+				assert.Len(t, cached, len(value))
+			}
+		}()
+	}
+	wg.Wait()
+
+	files, err := os.ReadDir(cache.Dir("github"))
+	assert.NoError(t, err)
+	for _, file := range files {
+		assert.NotContains(t, file.Name(), ".tmp", "temp files should be renamed or removed")
+	}
+}
+
+func TestEntriesAndClear(t *testing.T) {
+	cache := newTestCache(t)
+	assert.NoError(t, cache.Set("github", "acme", "repos", time.Hour))
+	assert.NoError(t, cache.Set("github", "@team/acme/platform", "repos", time.Hour))
+	assert.NoError(t, cache.Set("status", "default", "status", time.Hour))
+	var value string
+	cache.Get("github", "acme", &value)
+	cache.Get("github", "acme", &value)
+
+	entries, err := cache.Entries("")
+	assert.NoError(t, err)
+	if !assert.Len(t, entries, 3) {
+		return
+	}
+	assert.Equal(t, "github", entries[0].Source)
+	assert.Equal(t, "@team/acme/platform", entries[0].Scope)
+	assert.Equal(t, "acme", entries[1].Scope)
+	assert.Equal(t, 2, entries[1].Hits)
+	assert.Equal(t, "status", entries[2].Source)
+
+	assert.NoError(t, cache.Clear("github"))
+	entries, err = cache.Entries("")
+	assert.NoError(t, err)
+	if !assert.Len(t, entries, 1) {
+		return
+	}
+	assert.Equal(t, "status", entries[0].Source)
+
+	assert.NoError(t, cache.Clear(""))
+	entries, err = cache.Entries("")
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestFlush(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	h := home.NewHome(oswrap.NewOs())
+	cache := NewCache(h)
+	assert.NoError(t, cache.Set("github", "acme", "repos", time.Hour))
+	assert.NoError(t, cache.Set("preview", "/home/test/c/api", "output", 0))
+	hitsPath := filepath.Join(cache.Dir(""), hitsFile)
+
+	var value string
+	cache.Get("github", "acme", &value)
+	cache.Get("github", "acme", &value)
+	cache.Get("preview", "/home/test/c/api", &value)
+
+	t.Run("should count hits in memory", func(t *testing.T) {
+		_, err := os.Stat(hitsPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("should save hits and forget expired entries", func(t *testing.T) {
+		assert.NoError(t, cache.Flush())
+		data, err := os.ReadFile(hitsPath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"github/acme"`)
+		assert.NotContains(t, string(data), "/home/test/c/api")
+	})
+
+	t.Run("should add to the saved hits", func(t *testing.T) {
+		next := NewCache(h)
+		entry, _ := next.Get("github", "acme", &value)
+		assert.Equal(t, 3, entry.Hits)
+		assert.NoError(t, next.Flush())
+		entries, err := NewCache(h).Entries("github")
+		assert.NoError(t, err)
+		assert.Equal(t, 3, entries[0].Hits)
+	})
+}
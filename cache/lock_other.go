@@ -0,0 +1,8 @@
+//go:build !unix
+
+package cache
+
+// lock is a no-op where flock isn't available, writes are still atomic
+func lock(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,50 @@
+package cloner
+
+import (
+	"sync"
+
+	"github.com/joshmedeski/sesh/v2/model"
+)
+
+// DefaultJobs is how many repositories CloneAll clones at once by default
+const DefaultJobs = 4
+
+func (c *RealCloner) CloneAll(repos []model.GitCloneOptions, jobs int, progress func(done int, result model.CloneResult)) []model.CloneResult {
+	if jobs < 1 {
+		jobs = DefaultJobs
+	}
+	results := make([]model.CloneResult, len(repos))
+	pending := make(chan int)
+	finished := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(jobs, len(repos)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pending {
+				path, cloned, err := c.clone(repos[i])
+				results[i] = model.CloneResult{Repo: repos[i].Repo, Path: path, Skipped: err == nil && !cloned, Err: err}
+				finished <- i
+			}
+		}()
+	}
+	go func() {
+		for i := range repos {
+			pending <- i
+		}
+		close(pending)
+		wg.Wait()
+		close(finished)
+	}()
+
+	// Progress is reported from one goroutine, in the order repos finish
+	done := 0
+	for i := range finished {
+		done++
+		if progress != nil {
+			progress(done, results[i])
+		}
+	}
+	return results
+}
@@ -1,13 +1,36 @@
 package cloner
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/joshmedeski/sesh/v2/connector"
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/hooks"
 	"github.com/joshmedeski/sesh/v2/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+// newMockHome expands ~ to /home/test
+func newMockHome() *home.MockHome {
+	mockHome := new(home.MockHome)
+	mockHome.On("ExpandHome", mock.Anything).Return(func(path string) (string, error) {
+		return strings.Replace(path, "~", "/home/test", 1), nil
+	})
+	return mockHome
+}
+
+func newMockHooks() *hooks.MockHooks {
+	mockHooks := new(hooks.MockHooks)
+	mockHooks.On("PostClone", mock.Anything, mock.Anything).Return(nil)
+	return mockHooks
+}
+
 func TestListSessions(t *testing.T) {
 	t.Run("get path with both cmd and repo", func(t *testing.T) {
 		mockOpts := model.GitCloneOptions{Repo: "https://www.github.comtest/repo.git", CmdDir: "cmdDir", Dir: "dir"}
@@ -34,3 +57,164 @@ func TestListSessions(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	})
 }
+
+func TestCloneOptions(t *testing.T) {
+	setup := func() (*git.MockGit, *connector.MockConnector, Cloner) {
+		mockGit := new(git.MockGit)
+		mockConnector := new(connector.MockConnector)
+		return mockGit, mockConnector, NewCloner(mockConnector, mockGit, newMockHome(), model.Config{}, newMockHooks())
+	}
+	flags := model.GitCloneFlags{Depth: 1, Filter: "blob:none", RecurseSubmodules: true}
+
+	t.Run("should pass the flags to git clone", func(t *testing.T) {
+		mockGit, mockConnector, cloner := setup()
+		cmdDir := t.TempDir()
+		mockGit.On("Clone", "https://example.com/acme/api.git", cmdDir, "api", flags).Return("", nil)
+		mockConnector.On("Connect", cmdDir+"/api", model.ConnectOpts{}).Return("", nil)
+		path, err := cloner.Clone(model.GitCloneOptions{Repo: "https://example.com/acme/api.git", CmdDir: cmdDir, Dir: "api", GitCloneFlags: flags})
+		assert.Nil(t, err)
+		assert.Equal(t, cmdDir+"/api", path)
+	})
+
+	t.Run("should connect to the worktree of a bare clone", func(t *testing.T) {
+		mockGit, mockConnector, cloner := setup()
+		cmdDir := t.TempDir()
+		mockGit.On("CloneBareWorktree", "https://example.com/acme/api.git", cmdDir, "api", flags).Return("main", nil)
+		mockConnector.On("Connect", cmdDir+"/api/main", model.ConnectOpts{}).Return("", nil)
+		path, err := cloner.Clone(model.GitCloneOptions{Repo: "https://example.com/acme/api.git", CmdDir: cmdDir, Dir: "api", BareWorktree: true, GitCloneFlags: flags})
+		assert.Nil(t, err)
+		assert.Equal(t, cmdDir+"/api/main", path)
+		mockGit.AssertNotCalled(t, "Clone", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestClonePath(t *testing.T) {
+	config := model.Config{Clone: model.CloneConfig{
+		PathTemplate: "/src/{{.Host}}/{{.Path}}",
+		Hosts:        map[string]string{"git.corp": "/work/{{.Repo}}"},
+		Aliases:      map[string]string{"gl": "git@gitlab.com:{{.Path}}.git"},
+	}}
+	cloner := NewCloner(new(connector.MockConnector), new(git.MockGit), newMockHome(), config, newMockHooks())
+
+	t.Run("should keep the full namespace path", func(t *testing.T) {
+		path, err := cloner.ClonePath(model.GitCloneOptions{Repo: "git@gitlab.com:group/sub/repo.git"})
+		assert.Nil(t, err)
+		assert.Equal(t, "/src/gitlab.com/group/sub/repo", path)
+	})
+
+	t.Run("should use the template of the host", func(t *testing.T) {
+		path, err := cloner.ClonePath(model.GitCloneOptions{Repo: "ssh://git@git.corp:7999/team/service.git"})
+		assert.Nil(t, err)
+		assert.Equal(t, "/work/service", path)
+	})
+
+	t.Run("should expand GitHub shorthands", func(t *testing.T) {
+		path, err := cloner.ClonePath(model.GitCloneOptions{Repo: "joshmedeski/sesh"})
+		assert.Nil(t, err)
+		assert.Equal(t, "/src/github.com/joshmedeski/sesh", path)
+	})
+
+	t.Run("should expand aliases", func(t *testing.T) {
+		path, err := cloner.ClonePath(model.GitCloneOptions{Repo: "gl:group/sub/repo"})
+		assert.Nil(t, err)
+		assert.Equal(t, "/src/gitlab.com/group/sub/repo", path)
+	})
+
+	t.Run("should expand ~", func(t *testing.T) {
+		cloner := NewCloner(new(connector.MockConnector), new(git.MockGit), newMockHome(), model.Config{}, newMockHooks())
+		path, err := cloner.ClonePath(model.GitCloneOptions{Repo: "git@gitlab.com:group/repo.git"})
+		assert.Nil(t, err)
+		assert.Equal(t, "/home/test/git/gitlab.com/group/repo", path)
+	})
+
+	t.Run("should respect an explicit directory", func(t *testing.T) {
+		path, err := cloner.ClonePath(model.GitCloneOptions{Repo: "git@gitlab.com:group/sub/repo.git", CmdDir: "/tmp", Dir: "repo"})
+		assert.Nil(t, err)
+		assert.Equal(t, "/tmp/repo", path)
+	})
+}
+
+func TestCloneExisting(t *testing.T) {
+	setup := func() (*git.MockGit, *connector.MockConnector, Cloner, string) {
+		mockGit := new(git.MockGit)
+		mockConnector := new(connector.MockConnector)
+		cmdDir := t.TempDir()
+		assert.Nil(t, os.WriteFile(filepath.Join(cmdDir, "README.md"), nil, 0644))
+		return mockGit, mockConnector, NewCloner(mockConnector, mockGit, newMockHome(), model.Config{}, newMockHooks()), cmdDir
+	}
+
+	t.Run("should connect to a checkout of the repo", func(t *testing.T) {
+		mockGit, mockConnector, cloner, path := setup()
+		mockGit.On("RemoteURL", path).Return("git@example.com:acme/api.git", nil)
+		mockGit.On("Fetch", path, "origin", "").Return("", nil)
+		mockConnector.On("Connect", path, model.ConnectOpts{}).Return("", nil)
+		actual, err := cloner.Clone(model.GitCloneOptions{Repo: "https://example.com/acme/api", CmdDir: filepath.Dir(path), Dir: filepath.Base(path), Fetch: true})
+		assert.Nil(t, err)
+		assert.Equal(t, path, actual)
+		mockGit.AssertCalled(t, "Fetch", path, "origin", "")
+		mockGit.AssertNotCalled(t, "Clone", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should refuse a checkout of another repo", func(t *testing.T) {
+		mockGit, _, cloner, path := setup()
+		mockGit.On("RemoteURL", path).Return("git@example.com:acme/web.git", nil)
+		_, err := cloner.Clone(model.GitCloneOptions{Repo: "https://example.com/acme/api", CmdDir: filepath.Dir(path), Dir: filepath.Base(path)})
+		assert.ErrorContains(t, err, "the directory is a clone of git@example.com:acme/web.git")
+		mockGit.AssertNotCalled(t, "Clone", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should refuse a directory that isn't a repo", func(t *testing.T) {
+		mockGit, _, cloner, path := setup()
+		mockGit.On("RemoteURL", path).Return("", errors.New("exit status 128"))
+		_, err := cloner.Clone(model.GitCloneOptions{Repo: "https://example.com/acme/api", CmdDir: filepath.Dir(path), Dir: filepath.Base(path)})
+		assert.ErrorContains(t, err, "the directory exists and has no origin remote")
+	})
+}
+
+func TestCloneAll(t *testing.T) {
+	cloneDir := t.TempDir()
+	mockGit := new(git.MockGit)
+	mockConnector := new(connector.MockConnector)
+	config := model.Config{Clone: model.CloneConfig{PathTemplate: cloneDir + "/{{.Path}}"}}
+	cloner := NewCloner(mockConnector, mockGit, newMockHome(), config, newMockHooks())
+
+	existing := filepath.Join(cloneDir, "acme", "web")
+	assert.Nil(t, os.MkdirAll(existing, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(existing, "README.md"), nil, 0644))
+	mockGit.On("RemoteURL", existing).Return("git@example.com:acme/web.git", nil)
+	mockGit.On("Clone", "git@example.com:acme/api.git", filepath.Join(cloneDir, "acme"), "api", model.GitCloneFlags{}).Return("", nil)
+	mockGit.On("Clone", "git@example.com:acme/gone.git", filepath.Join(cloneDir, "acme"), "gone", model.GitCloneFlags{}).Return("", errors.New("exit status 128"))
+
+	repos := []model.GitCloneOptions{
+		{Repo: "git@example.com:acme/api.git"},
+		{Repo: "git@example.com:acme/web.git"},
+		{Repo: "git@example.com:acme/gone.git"},
+	}
+	var reported []int
+	results := cloner.CloneAll(repos, 2, func(done int, result model.CloneResult) {
+		reported = append(reported, done)
+	})
+
+	assert.Equal(t, []int{1, 2, 3}, reported)
+	assert.Equal(t, model.CloneResult{Repo: "git@example.com:acme/api.git", Path: filepath.Join(cloneDir, "acme", "api")}, results[0])
+	assert.Equal(t, model.CloneResult{Repo: "git@example.com:acme/web.git", Path: existing, Skipped: true}, results[1])
+	assert.EqualError(t, results[2].Err, "exit status 128")
+	mockConnector.AssertNotCalled(t, "Connect", mock.Anything, mock.Anything)
+}
+
+func TestCloneHooks(t *testing.T) {
+	cmdDir := t.TempDir()
+	mockGit := new(git.MockGit)
+	mockConnector := new(connector.MockConnector)
+	mockHooks := new(hooks.MockHooks)
+	cloner := NewCloner(mockConnector, mockGit, newMockHome(), model.Config{}, mockHooks)
+	mockGit.On("Clone", "https://example.com/acme/api.git", cmdDir, "api", mock.Anything).Return("", nil)
+	mockConnector.On("Connect", cmdDir+"/api", model.ConnectOpts{}).Return("", nil)
+	mockHooks.On("PostClone", cmdDir+"/api", false).Return(nil).Once()
+
+	_, err := cloner.Clone(model.GitCloneOptions{Repo: "https://example.com/acme/api.git", CmdDir: cmdDir, Dir: "api"})
+	assert.Nil(t, err)
+	_, err = cloner.Clone(model.GitCloneOptions{Repo: "https://example.com/acme/api.git", CmdDir: cmdDir, Dir: "api", NoHooks: true})
+	assert.Nil(t, err)
+	mockHooks.AssertExpectations(t)
+}
@@ -2,108 +2,207 @@ package cloner
 
 import (
 	"fmt"
-	"net/url"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/joshmedeski/sesh/v2/connector"
 	"github.com/joshmedeski/sesh/v2/git"
 	"github.com/joshmedeski/sesh/v2/github"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/hooks"
 	"github.com/joshmedeski/sesh/v2/model"
 )
 
 type Cloner interface {
 	// Clones a git repository
 	Clone(opts model.GitCloneOptions) (string, error)
+	// ClonePath returns where a repository would be cloned to
+	ClonePath(opts model.GitCloneOptions) (string, error)
+	// CloneAll clones repositories concurrently without connecting to them,
+	// progress is called as each one finishes
+	CloneAll(repos []model.GitCloneOptions, jobs int, progress func(done int, result model.CloneResult)) []model.CloneResult
 }
 
 type RealCloner struct {
 	connector connector.Connector
 	git       git.Git
+	home      home.Home
 	config    model.Config
+	hooks     hooks.Hooks
 	shorthand github.ShorthandConverter
 }
 
-func NewCloner(connector connector.Connector, git git.Git, config model.Config) Cloner {
+func NewCloner(connector connector.Connector, git git.Git, home home.Home, config model.Config, hooks hooks.Hooks) Cloner {
 	return &RealCloner{
 		connector: connector,
 		git:       git,
+		home:      home,
 		config:    config,
+		hooks:     hooks,
 		shorthand: github.NewShorthandConverter(),
 	}
 }
 
 func (c *RealCloner) Clone(opts model.GitCloneOptions) (string, error) {
-	var repoURL string
-	var clonePath string
-	
-	if c.shorthand.IsGitHubShorthand(opts.Repo) {
-		// Handle GitHub shorthand
-		var err error
-		repoURL, err = c.shorthand.ConvertToURL(opts.Repo, c.config.GitHub)
-		if err != nil {
-			return "", err
-		}
-		
-		// For GitHub repos, use smart clone path if not specified
-		if opts.CmdDir == "" && opts.Dir == "" {
-			org, repo, err := c.shorthand.ExtractOrgAndRepo(opts.Repo)
-			if err != nil {
-				return "", err
-			}
-			clonePath = c.shorthand.GetClonePath(org, repo, c.config.GitHub)
-			
-			// Split clonePath into cmdDir and dir
-			lastSlash := strings.LastIndex(clonePath, "/")
-			if lastSlash > 0 {
-				opts.CmdDir = clonePath[:lastSlash]
-				opts.Dir = clonePath[lastSlash+1:]
-			}
-		}
-	} else {
-		// Handle non-GitHub git repositories
-		repoURL = opts.Repo
-		
-		// If no custom path specified, organize by domain
-		if opts.CmdDir == "" && opts.Dir == "" {
-			domain, org, repo, err := c.parseGitURL(opts.Repo)
-			if err == nil && domain != "" && org != "" && repo != "" {
-				cloneDir := c.config.GitHub.CloneDir
-				if cloneDir == "" {
-					cloneDir = "~/git"
-				}
-				cloneDir = c.expandHome(cloneDir)
-				
-				// Create domain-based path: ~/git/domain.com/org/repo
-				clonePath = strings.Join([]string{cloneDir, domain, org, repo}, "/")
-				lastSlash := strings.LastIndex(clonePath, "/")
-				if lastSlash > 0 {
-					opts.CmdDir = clonePath[:lastSlash]
-					opts.Dir = clonePath[lastSlash+1:]
-				}
-			}
-		}
+	path, _, err := c.clone(opts)
+	if err != nil {
+		return "", err
+	}
+
+	newOpts := model.ConnectOpts{}
+	if _, err := c.connector.Connect(path, newOpts); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// clone clones a repository unless it's already checked out and runs the
+// post_clone hooks, it returns the path to connect to and whether the
+// repository was cloned
+func (c *RealCloner) clone(opts model.GitCloneOptions) (string, bool, error) {
+	path, cloned, err := c.checkout(opts)
+	if err != nil || opts.NoHooks {
+		return path, cloned, err
+	}
+	// Hooks that failed or were added since run when an existing checkout is
+	// cloned again
+	return path, cloned, c.hooks.PostClone(path, opts.Quiet)
+}
+
+func (c *RealCloner) checkout(opts model.GitCloneOptions) (string, bool, error) {
+	repoURL, opts, err := c.resolve(opts)
+	if err != nil {
+		return "", false, err
+	}
+
+	path := getPath(opts)
+	exists, err := c.checkExisting(repoURL, path)
+	if err != nil {
+		return "", false, err
+	}
+	if exists {
+		path, err := c.useExisting(path, opts)
+		return path, false, err
 	}
 
 	// Create parent directory if it doesn't exist
 	if opts.CmdDir != "" {
 		if err := os.MkdirAll(opts.CmdDir, 0755); err != nil {
-			return "", fmt.Errorf("failed to create directory %s: %w", opts.CmdDir, err)
+			return "", false, fmt.Errorf("failed to create directory %s: %w", opts.CmdDir, err)
 		}
 	}
 
-	if _, err := c.git.Clone(repoURL, opts.CmdDir, opts.Dir); err != nil {
-		return "", err
+	if opts.BareWorktree {
+		branch, err := c.git.CloneBareWorktree(repoURL, opts.CmdDir, opts.Dir, opts.GitCloneFlags)
+		if err != nil {
+			return "", false, err
+		}
+		// Sessions start in the worktree, not next to the bare repository
+		path = filepath.Join(path, branch)
+	} else if _, err := c.git.Clone(repoURL, opts.CmdDir, opts.Dir, opts.GitCloneFlags); err != nil {
+		return "", false, err
 	}
+	return path, true, nil
+}
 
-	path := getPath(opts)
+// checkExisting reports whether path is a checkout of repoURL, it can be
+// cloned into when it's missing or an empty directory
+func (c *RealCloner) checkExisting(repoURL, path string) (bool, error) {
+	entries, err := os.ReadDir(path)
+	if os.IsNotExist(err) || (err == nil && len(entries) == 0) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("can't clone %s to %s: %w", repoURL, path, err)
+	}
+	remote, err := c.git.RemoteURL(path)
+	if err != nil || remote == "" {
+		return false, fmt.Errorf("can't clone %s to %s: the directory exists and has no origin remote", repoURL, path)
+	}
+	if !model.SameRemote(remote, repoURL) {
+		return false, fmt.Errorf("can't clone %s to %s: the directory is a clone of %s", repoURL, path, remote)
+	}
+	return true, nil
+}
 
-	newOpts := model.ConnectOpts{}
-	if _, err := c.connector.Connect(path, newOpts); err != nil {
+// useExisting fetches an existing checkout if asked to, sessions start in the
+// worktree of the current branch for bare clones
+func (c *RealCloner) useExisting(path string, opts model.GitCloneOptions) (string, error) {
+	slog.Debug("Repository is already cloned", "path", path)
+	if opts.Fetch {
+		if _, err := c.git.Fetch(path, "origin", ""); err != nil {
+			return "", fmt.Errorf("failed to fetch %s: %w", path, err)
+		}
+	}
+	if opts.BareWorktree {
+		branch, err := c.git.CurrentBranch(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to find the branch of %s: %w", path, err)
+		}
+		path = filepath.Join(path, branch)
+	}
+	return path, nil
+}
+
+func (c *RealCloner) ClonePath(opts model.GitCloneOptions) (string, error) {
+	_, opts, err := c.resolve(opts)
+	if err != nil {
 		return "", err
 	}
+	return getPath(opts), nil
+}
 
-	return path, nil
+// resolve returns the url to clone, aliases and GitHub shorthands are
+// expanded. Unless the options name a directory, the clone path template of
+// the host decides where the repository goes.
+func (c *RealCloner) resolve(opts model.GitCloneOptions) (string, model.GitCloneOptions, error) {
+	repoURL, isAlias, err := c.config.Clone.ExpandAlias(opts.Repo)
+	if err != nil {
+		return "", opts, err
+	}
+	isShorthand := !isAlias && c.shorthand.IsGitHubShorthand(opts.Repo)
+	if isShorthand {
+		repoURL, err = c.shorthand.ConvertToURL(opts.Repo, c.config.GitHub)
+		if err != nil {
+			return "", opts, err
+		}
+	}
+	if opts.CmdDir != "" || opts.Dir != "" {
+		return repoURL, opts, nil
+	}
+
+	var clonePath string
+	if isShorthand {
+		org, repo, err := c.shorthand.ExtractOrgAndRepo(opts.Repo)
+		if err != nil {
+			return "", opts, err
+		}
+		clonePath, err = c.shorthand.GetClonePath(org, repo, c.config)
+		if err != nil {
+			return "", opts, err
+		}
+	} else {
+		remote, err := model.ParseGitURL(repoURL)
+		if err != nil {
+			// Urls without a namespace are cloned into the current directory
+			return repoURL, opts, nil
+		}
+		clonePath, err = c.config.Clone.ClonePath(remote, c.config.GitHub.CloneDir)
+		if err != nil {
+			return "", opts, err
+		}
+	}
+	clonePath, err = c.home.ExpandHome(clonePath)
+	if err != nil {
+		return "", opts, err
+	}
+
+	opts.CmdDir = filepath.Dir(clonePath)
+	opts.Dir = filepath.Base(clonePath)
+	return repoURL, opts, nil
 }
 
 func getPath(opts model.GitCloneOptions) string {
@@ -129,47 +228,3 @@ func getRepoName(url string) string {
 	repoName := strings.TrimSuffix(lastPart, ".git")
 	return repoName
 }
-
-// parseGitURL extracts domain, org, and repo from a git URL
-func (c *RealCloner) parseGitURL(gitURL string) (domain, org, repo string, err error) {
-	// Handle SSH URLs like git@domain.com:org/repo.git
-	if strings.HasPrefix(gitURL, "git@") {
-		parts := strings.Split(gitURL, ":")
-		if len(parts) != 2 {
-			return "", "", "", fmt.Errorf("invalid SSH git URL format")
-		}
-		
-		domain = strings.Split(parts[0], "@")[1]
-		pathParts := strings.Split(strings.TrimSuffix(parts[1], ".git"), "/")
-		if len(pathParts) >= 2 {
-			org = pathParts[0]
-			repo = pathParts[1]
-		}
-		return domain, org, repo, nil
-	}
-	
-	// Handle HTTPS URLs
-	u, err := url.Parse(gitURL)
-	if err != nil {
-		return "", "", "", err
-	}
-	
-	domain = u.Host
-	pathParts := strings.Split(strings.Trim(u.Path, "/"), "/")
-	if len(pathParts) >= 2 {
-		org = pathParts[0]
-		repo = strings.TrimSuffix(pathParts[1], ".git")
-	}
-	
-	return domain, org, repo, nil
-}
-
-// expandHome expands ~ to user home directory
-func (c *RealCloner) expandHome(path string) string {
-	if strings.HasPrefix(path, "~/") {
-		if homeDir, err := os.UserHomeDir(); err == nil {
-			return strings.Replace(path, "~", homeDir, 1)
-		}
-	}
-	return path
-}
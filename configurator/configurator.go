@@ -3,6 +3,10 @@ package configurator
 import (
 	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/joshmedeski/sesh/v2/model"
@@ -14,12 +18,16 @@ import (
 
 type Configurator interface {
 	GetConfig() (model.Config, error) // Since error is an interface, we use it here to return a single variable instead of multiple variables (configError holds 2 strings, human and err)
+	ConfigFiles() ([]ConfigFile, error)
+	ConfigPath() (string, error)
+	Validate() []error
 }
 
 type RealConfigurator struct {
-	os      oswrap.Os
-	path    pathwrap.Path
-	runtime runtimewrap.Runtime
+	os         oswrap.Os
+	path       pathwrap.Path
+	runtime    runtimewrap.Runtime
+	configPath string // Explicit config file path (--config flag), takes precedence over discovery
 }
 
 // Helper for consolidation of error into a single structure
@@ -36,8 +44,15 @@ func (ce *ConfigError) Human() string {
 	return ce.HumanDetails // Return the string
 }
 
-func NewConfigurator(os oswrap.Os, path pathwrap.Path, runtime runtimewrap.Runtime) Configurator {
-	return &RealConfigurator{os, path, runtime}
+// ConfigFile is a file that makes up the config (the main file or an import)
+type ConfigFile struct {
+	Path   string
+	Import bool // Whether the file was imported by the main config file
+	Exists bool
+}
+
+func NewConfigurator(os oswrap.Os, path pathwrap.Path, runtime runtimewrap.Runtime, configPath string) Configurator {
+	return &RealConfigurator{os, path, runtime, configPath}
 }
 
 func (c *RealConfigurator) configFilePath(rootDir string) string {
@@ -52,69 +67,207 @@ func (c *RealConfigurator) fullImportPath(homeDir, importPath string) (string, e
 	return c.path.Join(homeDir, importPath[1:]), nil
 }
 
-func (c *RealConfigurator) getConfigFileFromUserConfigDir() (model.Config, error) {
-	config := model.Config{}
-	evaluation := model.Evaluation{}
+// mainConfigFilePath resolves the main config file in order of precedence:
+// the --config flag, $SESH_CONFIG, $XDG_CONFIG_HOME/sesh/sesh.toml and ~/.config/sesh/sesh.toml
+func (c *RealConfigurator) mainConfigFilePath() (path string, explicit bool, err error) {
 	userHomeDir, err := c.os.UserHomeDir()
 	if err != nil {
-		return config, fmt.Errorf("couldn't get user config dir: %q", err)
-	}
-	userConfigDir := c.path.Join(userHomeDir, ".config")
-	configFilePath := c.configFilePath(userConfigDir)
-	file, _ := c.os.ReadFile(configFilePath)
-	// TODO: add to debugging logs (Update, added details string)
-	// if err != nil {
-	// 	return config, "", fmt.Errorf("couldn't read config file: %q", err)
-	// }
-	_ = toml.Unmarshal(file, &evaluation)
-	if evaluation.StrictMode {
-		reader := strings.NewReader(string(file))
-		d := toml.NewDecoder(reader)
+		return "", false, fmt.Errorf("couldn't get user config dir: %q", err)
+	}
+
+	for _, explicitPath := range []string{c.configPath, c.os.Getenv("SESH_CONFIG")} {
+		if explicitPath != "" {
+			path, err := c.fullImportPath(userHomeDir, explicitPath)
+			if err != nil {
+				return "", true, fmt.Errorf("couldn't get full config path: %q", err)
+			}
+			return path, true, nil
+		}
+	}
+
+	defaultPath := c.configFilePath(c.path.Join(userHomeDir, ".config"))
+	if xdgConfigHome := c.os.Getenv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
+		xdgPath := c.configFilePath(xdgConfigHome)
+		// Fall back to ~/.config when the file only exists there
+		if _, err := c.os.Stat(xdgPath); err == nil || xdgPath == defaultPath {
+			return xdgPath, false, nil
+		}
+		if _, err := c.os.Stat(defaultPath); err != nil {
+			return xdgPath, false, nil
+		}
+	}
+	return defaultPath, false, nil
+}
+
+// ConfigPath returns the path of the main config file
+func (c *RealConfigurator) ConfigPath() (string, error) {
+	path, _, err := c.mainConfigFilePath()
+	return path, err
+}
+
+// readConfigFile reads a config file, a missing file is not an error
+func (c *RealConfigurator) readConfigFile(path string) ([]byte, bool, error) {
+	file, err := c.os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("couldn't read config file %s: %w", path, err)
+	}
+	return file, true, nil
+}
+
+// decodeConfig decodes a config file, disallowing unknown fields in strict mode
+func decodeConfig(file []byte, strict bool, config *model.Config) error {
+	d := toml.NewDecoder(strings.NewReader(string(file)))
+	d.EnableUnmarshalerInterface() // startup_command can be a string or a list of steps
+	if strict {
 		d.DisallowUnknownFields() // enable the strict mode
-		err = d.Decode(&config)
+		err := d.Decode(config)
 		if err != nil {
 			var details *toml.StrictMissingError
 			if errors.As(err, &details) {
-				return config, &ConfigError{Err: err.Error(), HumanDetails: details.String()}
+				return &ConfigError{Err: err.Error(), HumanDetails: details.String()}
 			}
-			return config, err
-		}
-	} else {
-		err = toml.Unmarshal(file, &config)
-		if err != nil {
 			var derr *toml.DecodeError
 			if errors.As(err, &derr) {
-				return config, &ConfigError{Err: err.Error(), HumanDetails: derr.String()}
+				return &ConfigError{Err: err.Error(), HumanDetails: derr.String()}
 			}
+			return err
 		}
+		return nil
 	}
 
-	for _, importPath := range config.ImportPaths {
-		importFilePath, err := c.fullImportPath(userHomeDir, importPath)
-		if err != nil {
-			return config, fmt.Errorf("couldn't get full import path: %q", err)
+	err := d.Decode(config)
+	if err != nil {
+		var derr *toml.DecodeError
+		if errors.As(err, &derr) {
+			return &ConfigError{Err: err.Error(), HumanDetails: derr.String()}
 		}
+		return err
+	}
+	return nil
+}
+
+// configVisitor is called for the main config file and each import, it returns the decoded file
+type configVisitor func(file ConfigFile, raw []byte) (model.Config, error)
+
+// walkConfigFiles visits the main config file and its imports depth-first, in merge order
+func (c *RealConfigurator) walkConfigFiles(visit configVisitor) error {
+	userHomeDir, err := c.os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("couldn't get user config dir: %q", err)
+	}
+	configFilePath, explicit, err := c.mainConfigFilePath()
+	if err != nil {
+		return err
+	}
+	file, exists, err := c.readConfigFile(configFilePath)
+	if err != nil {
+		return err
+	}
+	if explicit && !exists {
+		return fmt.Errorf("config file %s does not exist", configFilePath)
+	}
+
+	seen := make(map[string]bool)
+	return c.walkConfigFile(ConfigFile{Path: configFilePath, Exists: exists}, file, userHomeDir, visit, nil, seen)
+}
 
-		importFile, err := c.os.ReadFile(importFilePath)
+func (c *RealConfigurator) walkConfigFile(file ConfigFile, raw []byte, homeDir string, visit configVisitor, stack []string, seen map[string]bool) error {
+	seen[file.Path] = true
+	config, err := visit(file, raw)
+	if err != nil {
+		return err
+	}
+
+	stack = append(stack, file.Path)
+	for _, importPath := range config.ImportPaths {
+		importFilePaths, err := c.importFilePaths(homeDir, filepath.Dir(file.Path), importPath)
 		if err != nil {
-			return config, fmt.Errorf("couldn't read import file %s: %q", importFilePath, err)
+			return fmt.Errorf("couldn't get full import path: %q", err)
 		}
 
-		importConfig := model.Config{}
-		if err := toml.Unmarshal(importFile, &importConfig); err != nil {
-			return config, fmt.Errorf("couldn't unmarshal import file %s: %q", importFilePath, err)
+		for _, importFilePath := range importFilePaths {
+			if slices.Contains(stack, importFilePath) {
+				return fmt.Errorf("import cycle: %s", strings.Join(append(stack, importFilePath), " -> "))
+			}
+			if seen[importFilePath] {
+				slog.Debug("Skipping config file that was already imported", "path", importFilePath)
+				continue
+			}
+
+			importFile, exists, err := c.readConfigFile(importFilePath)
+			if err != nil {
+				return err
+			}
+			importConfigFile := ConfigFile{Path: importFilePath, Import: true, Exists: exists}
+			if err := c.walkConfigFile(importConfigFile, importFile, homeDir, visit, stack, seen); err != nil {
+				return err
+			}
 		}
+	}
+
+	return nil
+}
 
-		config.SessionConfigs = append(config.SessionConfigs, importConfig.SessionConfigs...)
+// importFilePaths resolves an import to file paths, relative imports are relative to the
+// importing file and glob patterns expand to the matching files in lexical order
+func (c *RealConfigurator) importFilePaths(homeDir, baseDir, importPath string) ([]string, error) {
+	if strings.HasPrefix(importPath, "~") {
+		importPath = c.path.Join(homeDir, importPath[1:])
+	} else if !filepath.IsAbs(importPath) {
+		importPath = c.path.Join(baseDir, importPath)
 	}
 
-	return config, nil
+	if !strings.ContainsAny(importPath, "*?[") {
+		return []string{importPath}, nil
+	}
+	return c.path.Glob(importPath)
 }
 
 func (c *RealConfigurator) GetConfig() (model.Config, error) {
-	config, err := c.getConfigFileFromUserConfigDir()
+	config := model.Config{}
+	strict := false
+	err := c.walkConfigFiles(func(file ConfigFile, raw []byte) (model.Config, error) {
+		if !file.Exists {
+			if file.Import {
+				return model.Config{}, fmt.Errorf("couldn't read import file %s: file does not exist", file.Path)
+			}
+			return model.Config{}, nil
+		}
+
+		// Strict mode set in the main config file also applies to its imports
+		evaluation := model.Evaluation{}
+		_ = toml.Unmarshal(raw, &evaluation)
+		fileConfig := model.Config{}
+		if err := decodeConfig(raw, strict || evaluation.StrictMode, &fileConfig); err != nil {
+			if file.Import {
+				return model.Config{}, withFile(file.Path, err)
+			}
+			return model.Config{}, err
+		}
+		if !file.Import {
+			strict = evaluation.StrictMode
+		}
+
+		config = mergeConfig(config, fileConfig, configKeys(raw))
+		return fileConfig, nil
+	})
 	if err != nil {
 		return model.Config{}, err
 	}
 	return config, nil
 }
+
+// ConfigFiles returns the main config file followed by the files it imports
+func (c *RealConfigurator) ConfigFiles() ([]ConfigFile, error) {
+	var files []ConfigFile
+	err := c.walkConfigFiles(func(file ConfigFile, raw []byte) (model.Config, error) {
+		files = append(files, file)
+		config := model.Config{}
+		_ = decodeConfig(raw, false, &config)
+		return config, nil
+	})
+	return files, err
+}
@@ -0,0 +1,111 @@
+package configurator
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/joshmedeski/sesh/v2/pathwrap"
+	"github.com/joshmedeski/sesh/v2/runtimewrap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMainConfigFilePath(t *testing.T) {
+	setup := func(configPath string, env map[string]string, existing ...string) *RealConfigurator {
+		mockOs := new(oswrap.MockOs)
+		mockPath := new(pathwrap.MockPath)
+		mockOs.On("UserHomeDir").Return("/home/josh", nil)
+		mockOs.On("Getenv", mock.Anything).Return(func(key string) string { return env[key] })
+		mockOs.On("Stat", mock.Anything).Return(func(name string) (os.FileInfo, error) {
+			for _, file := range existing {
+				if file == name {
+					return nil, nil
+				}
+			}
+			return nil, os.ErrNotExist
+		})
+		mockPath.On("Join", mock.Anything, mock.Anything, mock.Anything).Return(func(elem ...string) string { return path.Join(elem...) })
+		mockPath.On("Join", mock.Anything, mock.Anything).Return(func(elem ...string) string { return path.Join(elem...) })
+		mockPath.On("Abs", mock.Anything).Return(func(p string) (string, error) { return path.Join("/cwd", p), nil })
+		return NewConfigurator(mockOs, mockPath, new(runtimewrap.MockRunTime), configPath).(*RealConfigurator)
+	}
+
+	t.Run("should default to ~/.config", func(t *testing.T) {
+		c := setup("", nil)
+		configPath, explicit, err := c.mainConfigFilePath()
+		assert.Nil(t, err)
+		assert.False(t, explicit)
+		assert.Equal(t, "/home/josh/.config/sesh/sesh.toml", configPath)
+	})
+
+	t.Run("should use $XDG_CONFIG_HOME", func(t *testing.T) {
+		c := setup("", map[string]string{"XDG_CONFIG_HOME": "/xdg"})
+		configPath, _, err := c.mainConfigFilePath()
+		assert.Nil(t, err)
+		assert.Equal(t, "/xdg/sesh/sesh.toml", configPath)
+	})
+
+	t.Run("should fall back to ~/.config when only it exists", func(t *testing.T) {
+		c := setup("", map[string]string{"XDG_CONFIG_HOME": "/xdg"}, "/home/josh/.config/sesh/sesh.toml")
+		configPath, _, err := c.mainConfigFilePath()
+		assert.Nil(t, err)
+		assert.Equal(t, "/home/josh/.config/sesh/sesh.toml", configPath)
+	})
+
+	t.Run("should prefer $SESH_CONFIG", func(t *testing.T) {
+		c := setup("", map[string]string{"XDG_CONFIG_HOME": "/xdg", "SESH_CONFIG": "~/dotfiles/sesh.toml"})
+		configPath, explicit, err := c.mainConfigFilePath()
+		assert.Nil(t, err)
+		assert.True(t, explicit)
+		assert.Equal(t, "/home/josh/dotfiles/sesh.toml", configPath)
+	})
+
+	t.Run("should prefer the --config flag", func(t *testing.T) {
+		c := setup("sesh.toml", map[string]string{"SESH_CONFIG": "~/dotfiles/sesh.toml"})
+		configPath, explicit, err := c.mainConfigFilePath()
+		assert.Nil(t, err)
+		assert.True(t, explicit)
+		assert.Equal(t, "/cwd/sesh.toml", configPath)
+	})
+}
+
+func TestDecodeStartupCommand(t *testing.T) {
+	t.Run("should decode a single command", func(t *testing.T) {
+		config := model.Config{}
+		err := decodeConfig([]byte(`[default_session]
+startup_command = "nvim"
+`), true, &config)
+		assert.Nil(t, err)
+		assert.Equal(t, model.NewStartupCommand("nvim"), config.DefaultSessionConfig.StartupCommand)
+	})
+
+	t.Run("should decode a list of steps", func(t *testing.T) {
+		config := model.Config{}
+		err := decodeConfig([]byte(`[[session]]
+name = "api"
+path = "~/c/api"
+startup_command = [
+  { run = "direnv allow", delay = "200ms" },
+  { run = "nvim", wait_for = "direnv: export", timeout = "30s" },
+  "git status",
+]
+`), true, &config)
+		assert.Nil(t, err)
+		assert.Equal(t, model.StartupCommand{
+			{Run: "direnv allow", Delay: "200ms"},
+			{Run: "nvim", WaitFor: "direnv: export", Timeout: "30s"},
+			{Run: "git status"},
+		}, config.SessionConfigs[0].StartupCommand)
+	})
+
+	t.Run("should reject unknown step fields", func(t *testing.T) {
+		config := model.Config{}
+		err := decodeConfig([]byte(`[default_session]
+startup_command = [{ run = "nvim", wait = "$ " }]
+`), false, &config)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,118 @@
+package configurator
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// mergeConfig merges a later config file into an earlier one, keys are the
+// decoded tables of the later file so values it sets explicitly, even false, 0
+// or "", are told apart from values it leaves out:
+//   - scalars set in the later file win
+//   - lists are appended
+//   - list entries with the same name (sessions, windows, organizations) are overridden
+//   - startup commands are overridden as a whole
+//   - tables of values (clone path templates, ...) are merged by key
+func mergeConfig(base, override model.Config, keys map[string]any) model.Config {
+	merged := base
+	mergeFields(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(override), keys)
+	return merged
+}
+
+// configKeys decodes the tables of a config file that was already decoded
+// successfully
+func configKeys(raw []byte) map[string]any {
+	keys := map[string]any{}
+	_ = toml.Unmarshal(raw, &keys)
+	return keys
+}
+
+// mergeFields merges the fields of a table that are present in its keys,
+// embedded structs share the keys of the table they're embedded in
+func mergeFields(dst, src reflect.Value, keys map[string]any) {
+	for i := 0; i < src.NumField(); i++ {
+		field := src.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if field.Anonymous && name == "" {
+			mergeFields(dst.Field(i), src.Field(i), keys)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if value, present := keys[name]; present {
+			mergeValue(dst.Field(i), src.Field(i), value)
+		}
+	}
+}
+
+func mergeValue(dst, src reflect.Value, value any) {
+	switch src.Kind() {
+	case reflect.Struct:
+		keys, _ := value.(map[string]any)
+		mergeFields(dst, src, keys)
+	case reflect.Slice:
+		if src.Len() == 0 {
+			return
+		}
+		// The steps of a startup command belong together
+		if src.Type() == reflect.TypeOf(model.StartupCommand{}) {
+			dst.Set(src)
+			return
+		}
+		merged := reflect.AppendSlice(reflect.MakeSlice(dst.Type(), 0, dst.Len()+src.Len()), dst)
+		if isNamed(src.Type().Elem()) {
+			merged = mergeNamed(merged, src)
+		} else {
+			merged = reflect.AppendSlice(merged, src)
+		}
+		dst.Set(merged)
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		merged := reflect.MakeMapWithSize(dst.Type(), dst.Len()+src.Len())
+		for _, from := range []reflect.Value{dst, src} {
+			iter := from.MapRange()
+			for iter.Next() {
+				merged.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+		dst.Set(merged)
+	default:
+		dst.Set(src)
+	}
+}
+
+// isNamed reports whether list entries are identified by their name
+func isNamed(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	field, exists := t.FieldByName("Name")
+	return exists && field.Type.Kind() == reflect.String
+}
+
+func mergeNamed(dst, src reflect.Value) reflect.Value {
+	for i := 0; i < src.Len(); i++ {
+		entry := src.Index(i)
+		name := entry.FieldByName("Name").String()
+		replaced := false
+		if name != "" {
+			for j := 0; j < dst.Len(); j++ {
+				if dst.Index(j).FieldByName("Name").String() == name {
+					dst.Index(j).Set(entry)
+					replaced = true
+					break
+				}
+			}
+		}
+		if !replaced {
+			dst = reflect.Append(dst, entry)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,157 @@
+package configurator
+
+import (
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// decodeOverride decodes a later config file like GetConfig does
+func decodeOverride(t *testing.T, raw string) (model.Config, map[string]any) {
+	config := model.Config{}
+	assert.Nil(t, decodeConfig([]byte(raw), false, &config))
+	return config, configKeys([]byte(raw))
+}
+
+func TestMergeConfig(t *testing.T) {
+	base := model.Config{
+		StrictMode: true,
+		Blacklist:  []string{"scratch"},
+		DefaultSessionConfig: model.DefaultSessionConfig{
+			StartupCommand: model.NewStartupCommand("nvim"),
+			PreviewCommand: "eza {}",
+		},
+		SessionConfigs: []model.SessionConfig{
+			{Name: "dotfiles", Path: "~/c/dotfiles"},
+			{Name: "notes", Path: "~/notes"},
+		},
+		WindowConfigs: []model.WindowConfig{{Name: "git", StartupScript: "git pull"}},
+		GitHub: model.GitHubConfig{
+			Organizations: []model.GitHubOrgConfig{{Name: "joshmedeski"}},
+			CacheTimeout:  30,
+		},
+		Clone: model.CloneConfig{Hosts: map[string]string{"gitlab.com": "~/gl/{{.Path}}", "github.com": "~/gh/{{.Path}}"}},
+	}
+	override, keys := decodeOverride(t, `
+blacklist = ["tmp"]
+
+[default_session]
+startup_command = "hx"
+
+[[session]]
+name = "dotfiles"
+path = "~/work/dotfiles"
+
+[[session]]
+name = "api"
+path = "~/work/api"
+
+[[window]]
+name = "git"
+startup_script = "lazygit"
+
+[github]
+show_description = false
+
+[[github.organizations]]
+name = "work"
+token = "token"
+
+[clone.hosts]
+"github.com" = "~/src/{{.Path}}"
+`)
+
+	merged := mergeConfig(base, override, keys)
+
+	t.Run("later scalars win", func(t *testing.T) {
+		assert.Equal(t, model.NewStartupCommand("hx"), merged.DefaultSessionConfig.StartupCommand)
+		assert.Equal(t, false, merged.GitHub.ShouldShowDescription())
+	})
+
+	t.Run("unset scalars are kept", func(t *testing.T) {
+		assert.True(t, merged.StrictMode)
+		assert.Equal(t, "eza {}", merged.DefaultSessionConfig.PreviewCommand)
+		assert.Equal(t, 30, merged.GitHub.CacheTimeout)
+	})
+
+	t.Run("lists are appended", func(t *testing.T) {
+		assert.Equal(t, []string{"scratch", "tmp"}, merged.Blacklist)
+		assert.Len(t, merged.GitHub.Organizations, 2)
+	})
+
+	t.Run("entries with the same name are overridden", func(t *testing.T) {
+		assert.Equal(t, []model.SessionConfig{
+			{Name: "dotfiles", Path: "~/work/dotfiles"},
+			{Name: "notes", Path: "~/notes"},
+			{Name: "api", Path: "~/work/api"},
+		}, merged.SessionConfigs)
+		assert.Equal(t, []model.WindowConfig{{Name: "git", StartupScript: "lazygit"}}, merged.WindowConfigs)
+	})
+
+	t.Run("tables are merged by key", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"gitlab.com": "~/gl/{{.Path}}", "github.com": "~/src/{{.Path}}"}, merged.Clone.Hosts)
+	})
+
+	t.Run("base is not modified", func(t *testing.T) {
+		assert.Equal(t, "~/c/dotfiles", base.SessionConfigs[0].Path)
+		assert.Equal(t, []string{"scratch"}, base.Blacklist)
+		assert.Equal(t, "~/gh/{{.Path}}", base.Clone.Hosts["github.com"])
+	})
+}
+
+func TestMergeConfigZeroValues(t *testing.T) {
+	base := model.Config{
+		StrictMode: true,
+		DefaultSessionConfig: model.DefaultSessionConfig{
+			PreviewCommand: "eza {}",
+		},
+		SessionConfigs: []model.SessionConfig{{Name: "dotfiles", Path: "~/c/dotfiles"}},
+		GitHub: model.GitHubConfig{
+			CacheTimeout:    30,
+			UseSSH:          true,
+			IncludePersonal: true,
+			GitHubRepoFilter: model.GitHubRepoFilter{
+				NamePattern: "^api",
+			},
+		},
+		Clone: model.CloneConfig{Depth: 1, RecurseSubmodules: true},
+	}
+	override, keys := decodeOverride(t, `
+strict_mode = false
+
+[default_session]
+preview_command = ""
+
+[github]
+cache_timeout = 0
+use_ssh = false
+name_pattern = ""
+
+[clone]
+depth = 0
+`)
+
+	merged := mergeConfig(base, override, keys)
+
+	t.Run("later false values win", func(t *testing.T) {
+		assert.False(t, merged.StrictMode)
+		assert.False(t, merged.GitHub.UseSSH)
+	})
+
+	t.Run("later zero numbers win", func(t *testing.T) {
+		assert.Equal(t, 0, merged.GitHub.CacheTimeout)
+		assert.Equal(t, 0, merged.Clone.Depth)
+	})
+
+	t.Run("later empty strings win", func(t *testing.T) {
+		assert.Equal(t, "", merged.DefaultSessionConfig.PreviewCommand)
+		assert.Equal(t, "", merged.GitHub.NamePattern)
+	})
+
+	t.Run("keys that aren't set are kept", func(t *testing.T) {
+		assert.True(t, merged.GitHub.IncludePersonal)
+		assert.True(t, merged.Clone.RecurseSubmodules)
+		assert.Equal(t, []model.SessionConfig{{Name: "dotfiles", Path: "~/c/dotfiles"}}, merged.SessionConfigs)
+	})
+}
@@ -0,0 +1,123 @@
+package configurator
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/joshmedeski/sesh/v2/model"
+)
+
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// schemaNode is the subset of JSON Schema needed to describe the config
+type schemaNode struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+	Items                *schemaNode            `json:"items,omitempty"`
+	Properties           map[string]*schemaNode `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+	AnyOf                []*schemaNode          `json:"anyOf,omitempty"`
+}
+
+// Schema generates a JSON Schema for sesh.toml from the toml, description and
+// default tags of model.Config
+func Schema() ([]byte, error) {
+	root := schemaFor(reflect.TypeOf(model.Config{}))
+	root.Schema = schemaDraft
+	root.Title = "sesh config"
+	return json.MarshalIndent(root, "", "  ")
+}
+
+func schemaFor(t reflect.Type) *schemaNode {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// A startup command is a single command or a list of steps
+	if t == reflect.TypeOf(model.StartupCommand{}) {
+		steps := schemaFor(t.Elem())
+		steps.Required = []string{"run"}
+		return &schemaNode{AnyOf: []*schemaNode{
+			{Type: "string"},
+			{Type: "array", Items: &schemaNode{AnyOf: []*schemaNode{{Type: "string"}, steps}}},
+		}}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		node := &schemaNode{
+			Type:                 "object",
+			Properties:           make(map[string]*schemaNode),
+			AdditionalProperties: false,
+		}
+		addProperties(node, t)
+		return node
+	case reflect.Map:
+		return &schemaNode{Type: "object", AdditionalProperties: schemaFor(t.Elem())}
+	case reflect.Slice, reflect.Array:
+		return &schemaNode{Type: "array", Items: schemaFor(t.Elem())}
+	case reflect.Bool:
+		return &schemaNode{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schemaNode{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &schemaNode{Type: "number"}
+	case reflect.String:
+		return &schemaNode{Type: "string"}
+	default:
+		return &schemaNode{}
+	}
+}
+
+func addProperties(node *schemaNode, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if key == "-" {
+			continue
+		}
+		// Embedded structs without a key are flattened, like the toml decoder does
+		if field.Anonymous && key == "" && field.Type.Kind() == reflect.Struct {
+			addProperties(node, field.Type)
+			continue
+		}
+		if key == "" {
+			key = field.Name
+		}
+
+		property := schemaFor(field.Type)
+		property.Description = field.Tag.Get("description")
+		if value, ok := field.Tag.Lookup("default"); ok {
+			property.Default = parseDefault(property.Type, value)
+		}
+		node.Properties[key] = property
+	}
+}
+
+func parseDefault(kind, value string) any {
+	switch kind {
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "integer":
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
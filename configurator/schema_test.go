@@ -0,0 +1,43 @@
+package configurator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema(t *testing.T) {
+	data, err := Schema()
+	assert.Nil(t, err)
+
+	var schema map[string]any
+	assert.Nil(t, json.Unmarshal(data, &schema))
+	assert.Equal(t, schemaDraft, schema["$schema"])
+	assert.Equal(t, "object", schema["type"])
+	assert.Equal(t, false, schema["additionalProperties"])
+
+	properties := schema["properties"].(map[string]any)
+
+	t.Run("should describe nested tables with their defaults", func(t *testing.T) {
+		github := properties["github"].(map[string]any)
+		assert.Equal(t, "object", github["type"])
+		timeout := github["properties"].(map[string]any)["cache_timeout"].(map[string]any)
+		assert.Equal(t, "integer", timeout["type"])
+		assert.Equal(t, float64(30), timeout["default"])
+		assert.NotEmpty(t, timeout["description"])
+		uncloned := github["properties"].(map[string]any)["show_uncloned"].(map[string]any)
+		assert.Equal(t, "boolean", uncloned["type"])
+		assert.Equal(t, true, uncloned["default"])
+	})
+
+	t.Run("should flatten embedded session settings", func(t *testing.T) {
+		session := properties["session"].(map[string]any)
+		assert.Equal(t, "array", session["type"])
+		sessionProperties := session["items"].(map[string]any)["properties"].(map[string]any)
+		assert.Contains(t, sessionProperties, "name")
+		assert.Contains(t, sessionProperties, "startup_command")
+		windows := sessionProperties["windows"].(map[string]any)
+		assert.Equal(t, "string", windows["items"].(map[string]any)["type"])
+	})
+}
@@ -0,0 +1,133 @@
+package configurator
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/joshmedeski/sesh/v2/shell"
+)
+
+// TokenResolver resolves the tokens of the GitHub and GitLab configs
+type TokenResolver interface {
+	// GitHubToken returns the token of an organization, or the global token
+	// for an empty name. The global token and $GITHUB_TOKEN are github.com
+	// credentials, organizations on other hosts only use their own token or
+	// the gh CLI's token for their host.
+	GitHubToken(config model.GitHubConfig, org string) string
+	// GitLabToken returns the configured token or $GITLAB_TOKEN
+	GitLabToken(config model.GitLabConfig) string
+}
+
+// RealTokenResolver only runs token commands and reads token files when a
+// token is needed, and once, so a password manager is only asked once
+type RealTokenResolver struct {
+	os     oswrap.Os
+	home   home.Home
+	shell  shell.Shell
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func NewTokenResolver(os oswrap.Os, home home.Home, shell shell.Shell) TokenResolver {
+	return &RealTokenResolver{
+		os:     os,
+		home:   home,
+		shell:  shell,
+		tokens: make(map[string]string),
+	}
+}
+
+func (r *RealTokenResolver) GitHubToken(config model.GitHubConfig, org string) string {
+	host := model.DefaultGitHubHost
+	if orgConfig, ok := config.GetOrgConfig(org); ok {
+		if token := r.resolve(orgConfig.Token, orgConfig.TokenCommand, orgConfig.TokenFile); token != "" {
+			return token
+		}
+		host = orgConfig.GetHost()
+	}
+
+	if host == model.DefaultGitHubHost {
+		if token := r.resolve(config.Token, config.TokenCommand, config.TokenFile); token != "" {
+			return token
+		}
+		if token := r.os.Getenv("GITHUB_TOKEN"); token != "" {
+			return token
+		}
+	}
+
+	return r.ghAuthToken(host)
+}
+
+func (r *RealTokenResolver) GitLabToken(config model.GitLabConfig) string {
+	if token := r.resolve(config.Token, config.TokenCommand, config.TokenFile); token != "" {
+		return token
+	}
+	return r.os.Getenv("GITLAB_TOKEN")
+}
+
+// resolve returns the first token found in the token, token_command and
+// token_file settings
+func (r *RealTokenResolver) resolve(token, command, file string) string {
+	if token != "" {
+		return token
+	}
+	if command != "" {
+		if token := r.cached("command:"+command, func() (string, error) { return r.tokenFromCommand(command) }); token != "" {
+			return token
+		}
+	}
+	if file != "" {
+		return r.cached("file:"+file, func() (string, error) { return r.tokenFromFile(file) })
+	}
+	return ""
+}
+
+// ghAuthToken returns the token of the gh CLI for the host, when it is
+// installed and logged in
+func (r *RealTokenResolver) ghAuthToken(host string) string {
+	return r.cached("gh:"+host, func() (string, error) {
+		token, err := r.shell.Cmd("gh", "auth", "token", "--hostname", host)
+		if err != nil {
+			return "", nil // not installed or not logged in
+		}
+		return strings.TrimSpace(token), nil
+	})
+}
+
+func (r *RealTokenResolver) cached(key string, resolve func() (string, error)) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token, ok := r.tokens[key]; ok {
+		return token
+	}
+	token, err := resolve()
+	if err != nil {
+		slog.Error("Failed to resolve token", "source", key, "error", err)
+	}
+	r.tokens[key] = token
+	return token
+}
+
+func (r *RealTokenResolver) tokenFromCommand(command string) (string, error) {
+	token, err := r.shell.Cmd("sh", "-c", command)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(token), nil
+}
+
+func (r *RealTokenResolver) tokenFromFile(file string) (string, error) {
+	path, err := r.home.ExpandHome(file)
+	if err != nil {
+		return "", err
+	}
+	content, err := r.os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
@@ -0,0 +1,96 @@
+package configurator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/joshmedeski/sesh/v2/shell"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGitHubToken(t *testing.T) {
+	config := model.GitHubConfig{
+		TokenFile: "~/.config/gh/token",
+		Organizations: []model.GitHubOrgConfig{
+			{Name: "work", TokenCommand: "pass show gh/work"},
+			{Name: "plain", Token: "plain-token", TokenCommand: "exit 1"},
+			{Name: "broken", TokenCommand: "exit 1"},
+			{Name: "acme", Host: "github.example.com"},
+		},
+	}
+
+	setup := func(env map[string]string) (*oswrap.MockOs, *shell.MockShell, TokenResolver) {
+		mockOs := new(oswrap.MockOs)
+		mockHome := new(home.MockHome)
+		mockShell := new(shell.MockShell)
+		mockOs.On("Getenv", mock.Anything).Return(func(key string) string { return env[key] })
+		mockOs.On("ReadFile", "/home/josh/.config/gh/token").Return([]byte("file-token\n"), nil)
+		mockHome.On("ExpandHome", "~/.config/gh/token").Return("/home/josh/.config/gh/token", nil)
+		mockShell.On("Cmd", "sh", "-c", "pass show gh/work").Return("work-token\n", nil)
+		mockShell.On("Cmd", "sh", "-c", "exit 1").Return("", errors.New("exit status 1"))
+		mockShell.On("Cmd", "gh", "auth", "token", "--hostname", "github.example.com").Return("", errors.New("not logged in"))
+		return mockOs, mockShell, NewTokenResolver(mockOs, mockHome, mockShell)
+	}
+
+	t.Run("should prefer the plain token", func(t *testing.T) {
+		_, mockShell, tokens := setup(nil)
+		assert.Equal(t, "plain-token", tokens.GitHubToken(config, "plain"))
+		mockShell.AssertNotCalled(t, "Cmd", "sh", "-c", "exit 1")
+	})
+
+	t.Run("should run the org token command", func(t *testing.T) {
+		_, _, tokens := setup(nil)
+		assert.Equal(t, "work-token", tokens.GitHubToken(config, "work"))
+	})
+
+	t.Run("should fall back to the global token file", func(t *testing.T) {
+		_, _, tokens := setup(nil)
+		assert.Equal(t, "file-token", tokens.GitHubToken(config, "broken"))
+		assert.Equal(t, "file-token", tokens.GitHubToken(config, ""))
+	})
+
+	t.Run("should fall back to $GITHUB_TOKEN and the gh CLI", func(t *testing.T) {
+		_, mockShell, tokens := setup(map[string]string{"GITHUB_TOKEN": "env-token"})
+		assert.Equal(t, "env-token", tokens.GitHubToken(model.GitHubConfig{}, ""))
+
+		_, mockShell, tokens = setup(nil)
+		mockShell.On("Cmd", "gh", "auth", "token", "--hostname", "github.com").Return("gh-token\n", nil)
+		assert.Equal(t, "gh-token", tokens.GitHubToken(model.GitHubConfig{}, ""))
+	})
+
+	t.Run("should not use github.com tokens for other hosts", func(t *testing.T) {
+		_, _, tokens := setup(map[string]string{"GITHUB_TOKEN": "env-token"})
+		enterprise := model.GitHubConfig{
+			Token:         "global-token",
+			Organizations: []model.GitHubOrgConfig{{Name: "acme", Host: "github.example.com"}},
+		}
+		assert.Equal(t, "", tokens.GitHubToken(enterprise, "acme"))
+		enterprise.Organizations[0].Token = "acme-token"
+		assert.Equal(t, "acme-token", tokens.GitHubToken(enterprise, "acme"))
+	})
+
+	t.Run("should resolve each token once", func(t *testing.T) {
+		mockOs, mockShell, tokens := setup(nil)
+		assert.Equal(t, "work-token", tokens.GitHubToken(config, "work"))
+		assert.Equal(t, "work-token", tokens.GitHubToken(config, "work"))
+		assert.Equal(t, "file-token", tokens.GitHubToken(config, ""))
+		assert.Equal(t, "file-token", tokens.GitHubToken(config, ""))
+		mockShell.AssertNumberOfCalls(t, "Cmd", 1)
+		mockOs.AssertNumberOfCalls(t, "ReadFile", 1)
+	})
+}
+
+func TestGitLabToken(t *testing.T) {
+	mockOs := new(oswrap.MockOs)
+	mockShell := new(shell.MockShell)
+	mockOs.On("Getenv", "GITLAB_TOKEN").Return("env-token")
+	mockShell.On("Cmd", "sh", "-c", "pass show gitlab").Return("command-token", nil)
+	tokens := NewTokenResolver(mockOs, new(home.MockHome), mockShell)
+
+	assert.Equal(t, "command-token", tokens.GitLabToken(model.GitLabConfig{TokenCommand: "pass show gitlab"}))
+	assert.Equal(t, "env-token", tokens.GitLabToken(model.GitLabConfig{}))
+}
@@ -0,0 +1,342 @@
+package configurator
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+var validSources = []string{"tmux", "config", "tmuxinator", "zoxide", "github", "gitlab"}
+
+type parsedConfigFile struct {
+	ConfigFile
+	raw    []byte
+	config model.Config
+}
+
+// Validate strictly parses the main config file and every import, then checks the
+// references between them. It returns every problem found instead of stopping at the first.
+func (c *RealConfigurator) Validate() []error {
+	var errs []error
+	var parsed []parsedConfigFile
+	err := c.walkConfigFiles(func(file ConfigFile, raw []byte) (model.Config, error) {
+		if !file.Exists {
+			// sesh runs without a config file, only a missing import is a mistake
+			if file.Import {
+				errs = append(errs, fmt.Errorf("%s: import file does not exist", file.Path))
+			}
+			return model.Config{}, nil
+		}
+
+		config := model.Config{}
+		if err := decodeConfig(raw, true, &config); err != nil {
+			errs = append(errs, withFile(file.Path, err))
+			// Keep following the imports of the file
+			lenient := model.Config{}
+			_ = decodeConfig(raw, false, &lenient)
+			return lenient, nil
+		}
+		parsed = append(parsed, parsedConfigFile{file, raw, config})
+		return config, nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return append(errs, validateReferences(parsed)...)
+}
+
+func validateReferences(files []parsedConfigFile) []error {
+	var errs []error
+
+	merged := model.Config{}
+	for _, file := range files {
+		merged = mergeConfig(merged, file.config, configKeys(file.raw))
+	}
+
+	windows := make(map[string]bool)
+	for _, window := range merged.WindowConfigs {
+		windows[window.Name] = true
+	}
+
+	for _, file := range files {
+		fileWindows := make(map[string]bool)
+		for i, window := range file.config.WindowConfigs {
+			if fileWindows[window.Name] {
+				errs = append(errs, referenceError(file, fmt.Sprintf("window[%d].name", i), window.Name, "window %q is defined more than once", window.Name))
+			}
+			fileWindows[window.Name] = true
+		}
+
+		for _, window := range file.config.DefaultSessionConfig.Windows {
+			if !windows[window] {
+				errs = append(errs, referenceError(file, "default_session.windows", window, "window %q used by default_session is not defined", window))
+			}
+		}
+
+		// Sessions with the same name in a later file override earlier ones, so
+		// duplicates are only a problem within a single file
+		sessions := make(map[string]bool)
+		for i, session := range file.config.SessionConfigs {
+			key := fmt.Sprintf("session[%d]", i)
+			if session.Name == "" {
+				errs = append(errs, referenceError(file, key, "", "session without a name"))
+				continue
+			}
+			if sessions[session.Name] {
+				errs = append(errs, referenceError(file, key+".name", session.Name, "session %q is defined more than once", session.Name))
+			}
+			sessions[session.Name] = true
+
+			if len(session.StartupCommand) > 0 && session.DisableStartCommand {
+				errs = append(errs, referenceError(file, key+".disable_startup_command", "", "session %q sets both startup_command and disable_startup_command", session.Name))
+			}
+			errs = append(errs, validateStartupCommand(file, key+".startup_command", session.StartupCommand)...)
+			for _, window := range session.Windows {
+				if !windows[window] {
+					errs = append(errs, referenceError(file, key+".windows", window, "window %q used by session %q is not defined", window, session.Name))
+				}
+			}
+		}
+
+		errs = append(errs, validateStartupCommand(file, "default_session.startup_command", file.config.DefaultSessionConfig.StartupCommand)...)
+
+		for i, rule := range file.config.Rules {
+			key := fmt.Sprintf("rule[%d]", i)
+			errs = append(errs, validateStartupCommand(file, key+".startup_command", rule.StartupCommand)...)
+			for _, window := range rule.Windows {
+				if !windows[window] {
+					errs = append(errs, referenceError(file, key+".windows", window, "window %q used by rule %q is not defined", window, rule.Name))
+				}
+			}
+			if rule.Remote == "" {
+				continue
+			}
+			if _, err := regexp.Compile(rule.Remote); err != nil {
+				errs = append(errs, referenceError(file, key+".remote", rule.Remote, "invalid remote pattern %q in rule %q: %s", rule.Remote, rule.Name, err))
+			}
+		}
+
+		for i, hook := range file.config.PostClone {
+			key := fmt.Sprintf("post_clone[%d]", i)
+			if len(hook.Commands) == 0 {
+				errs = append(errs, referenceError(file, key+".name", hook.Name, "post_clone hook %s has no commands", hook.ID()))
+			}
+			if _, err := path.Match(hook.Host, ""); err != nil {
+				errs = append(errs, referenceError(file, key+".host", hook.Host, "invalid host pattern %q in post_clone hook %s: %s", hook.Host, hook.ID(), err))
+			}
+			if _, err := filepath.Match(hook.Path, ""); err != nil {
+				errs = append(errs, referenceError(file, key+".path", hook.Path, "invalid path pattern %q in post_clone hook %s: %s", hook.Path, hook.ID(), err))
+			}
+		}
+
+		errs = append(errs, validateRepoFilter(file, "github", "[github]", file.config.GitHub.GitHubRepoFilter)...)
+		for i, org := range file.config.GitHub.Organizations {
+			errs = append(errs, validateRepoFilter(file, fmt.Sprintf("github.organizations[%d]", i), org.Name, org.GitHubRepoFilter)...)
+		}
+		for _, team := range file.config.GitHub.Teams {
+			if org, slug, ok := strings.Cut(team, "/"); !ok || org == "" || slug == "" || strings.Contains(slug, "/") {
+				errs = append(errs, referenceError(file, "github.teams", team, "invalid GitHub team %q, expected org/team-slug", team))
+			}
+		}
+
+		errs = append(errs, validateClonePathTemplate(file, "clone.path_template", "path_template", file.config.Clone.PathTemplate)...)
+		for _, host := range slices.Sorted(maps.Keys(file.config.Clone.Hosts)) {
+			errs = append(errs, validateClonePathTemplate(file, "clone.hosts."+host, host, file.config.Clone.Hosts[host])...)
+		}
+		for _, alias := range slices.Sorted(maps.Keys(file.config.Clone.Aliases)) {
+			errs = append(errs, validateCloneAlias(file, "clone.aliases."+alias, alias, file.config.Clone.Aliases[alias])...)
+		}
+
+		for _, pattern := range file.config.Blacklist {
+			if _, err := regexp.Compile(pattern); err != nil {
+				errs = append(errs, referenceError(file, "blacklist", pattern, "invalid blacklist pattern %q: %s", pattern, err))
+			}
+		}
+
+		for _, src := range file.config.SortOrder {
+			if !isValidSource(src) {
+				errs = append(errs, referenceError(file, "sort_order", src, "unknown source %q in sort_order, expected one of %s", src, strings.Join(validSources, ", ")))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateStartupCommand(file parsedConfigFile, key string, command model.StartupCommand) []error {
+	var errs []error
+	for _, step := range command {
+		if step.WaitFor != "" {
+			if _, err := regexp.Compile(step.WaitFor); err != nil {
+				errs = append(errs, referenceError(file, key, step.WaitFor, "invalid wait_for pattern %q: %s", step.WaitFor, err))
+			}
+		}
+		for _, duration := range []string{step.Delay, step.Timeout} {
+			if duration == "" {
+				continue
+			}
+			if _, err := time.ParseDuration(duration); err != nil {
+				errs = append(errs, referenceError(file, key, duration, "invalid duration %q in startup step %q, expected e.g. 500ms or 10s", duration, step.Run))
+			}
+		}
+	}
+	return errs
+}
+
+func validateRepoFilter(file parsedConfigFile, key string, owner string, filter model.GitHubRepoFilter) []error {
+	var errs []error
+	patterns := []struct{ field, pattern string }{{"name_pattern", filter.NamePattern}, {"exclude_pattern", filter.ExcludePattern}}
+	for _, p := range patterns {
+		pattern := p.pattern
+		if pattern == "" {
+			continue
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, referenceError(file, key+"."+p.field, pattern, "invalid repository pattern %q for %s: %s", pattern, owner, err))
+		}
+	}
+	if filter.Visibility != "" && !slices.Contains(model.GitHubVisibilities, filter.Visibility) {
+		errs = append(errs, referenceError(file, key+".visibility", filter.Visibility, "invalid visibility %q for %s, expected one of %s", filter.Visibility, owner, strings.Join(model.GitHubVisibilities, ", ")))
+	}
+	if filter.PushedWithin != "" {
+		if _, err := model.ParseAge(filter.PushedWithin); err != nil {
+			errs = append(errs, referenceError(file, key+".pushed_within", filter.PushedWithin, "invalid pushed_within %q for %s, %s", filter.PushedWithin, owner, err))
+		}
+	}
+	return errs
+}
+
+func validateClonePathTemplate(file parsedConfigFile, key string, owner string, tmpl string) []error {
+	if tmpl == "" {
+		return nil
+	}
+	clone := model.CloneConfig{PathTemplate: tmpl}
+	if _, err := clone.ClonePath(model.GitRemote{Host: "example.com", Path: "group/repo"}, ""); err != nil {
+		return []error{referenceError(file, key, tmpl, "invalid clone path template %q for %s: %s", tmpl, owner, errors.Unwrap(err))}
+	}
+	return nil
+}
+
+func validateCloneAlias(file parsedConfigFile, key string, alias string, tmpl string) []error {
+	clone := model.CloneConfig{Aliases: map[string]string{alias: tmpl}}
+	repoURL, _, err := clone.ExpandAlias(alias + ":group/repo")
+	if err != nil {
+		return []error{referenceError(file, key, tmpl, "invalid clone alias %q for %s: %s", tmpl, alias, errors.Unwrap(err))}
+	}
+	if _, err := model.ParseGitURL(repoURL); err != nil {
+		return []error{referenceError(file, key, tmpl, "invalid clone alias %q for %s: %s", tmpl, alias, err)}
+	}
+	return nil
+}
+
+func isValidSource(src string) bool {
+	for _, valid := range validSources {
+		if strings.EqualFold(src, valid) {
+			return true
+		}
+	}
+	return false
+}
+
+func withFile(path string, err error) error {
+	if human, ok := err.(*ConfigError); ok {
+		return &ConfigError{Err: fmt.Sprintf("%s: %s", path, human.Err), HumanDetails: human.HumanDetails}
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// referenceError builds a ConfigError pointing at the line of value, it's
+// searched from the line of key (like session[1].windows) since a value can be
+// mentioned in other places, e.g. by duplicates
+func referenceError(file parsedConfigFile, key string, value string, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	lines := strings.Split(string(file.raw), "\n")
+	at := func(i int) error {
+		return &ConfigError{
+			Err:          fmt.Sprintf("%s:%d: %s", file.Path, i+1, msg),
+			HumanDetails: fmt.Sprintf("%d| %s", i+1, lines[i]),
+		}
+	}
+
+	start, found := keyLine(keyLines(file.raw), key)
+	if value != "" {
+		for _, needle := range []string{fmt.Sprintf("%q", value), value} {
+			for i := start; i < len(lines); i++ {
+				if strings.Contains(lines[i], needle) {
+					return at(i)
+				}
+			}
+		}
+	}
+	if found {
+		return at(start)
+	}
+	return &ConfigError{Err: fmt.Sprintf("%s: %s", file.Path, msg)}
+}
+
+// keyLine returns the index of the line of key, or of the closest table
+// that contains it
+func keyLine(lines map[string]int, key string) (int, bool) {
+	for key != "" {
+		if line, ok := lines[key]; ok {
+			return line, true
+		}
+		i := strings.LastIndexAny(key, ".[")
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return 0, false
+}
+
+// keyLines maps the keys and tables of a config file to the index of their
+// line. Array tables are numbered like session[1] and keys are joined with dots.
+func keyLines(raw []byte) map[string]int {
+	lines := make(map[string]int)
+	counts := make(map[string]int)
+	table := ""
+	p := unstable.Parser{}
+	p.Reset(raw)
+	for p.NextExpression() {
+		expression := p.Expression()
+		var names []string
+		line := -1
+		keys := expression.Key()
+		for keys.Next() {
+			names = append(names, string(keys.Node().Data))
+			if line < 0 {
+				line = p.Shape(keys.Node().Raw).Start.Line - 1
+			}
+		}
+		name := strings.Join(names, ".")
+
+		switch expression.Kind {
+		case unstable.Table:
+			table = name
+		case unstable.ArrayTable:
+			table = fmt.Sprintf("%s[%d]", name, counts[name])
+			counts[name]++
+		case unstable.KeyValue:
+			if table != "" {
+				name = table + "." + name
+			}
+			lines[name] = line
+			continue
+		default:
+			continue
+		}
+		lines[table] = line
+	}
+	return lines
+}
@@ -0,0 +1,188 @@
+package configurator
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/joshmedeski/sesh/v2/pathwrap"
+	"github.com/joshmedeski/sesh/v2/runtimewrap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func parseTestFile(t *testing.T, path string, raw string, isImport bool) parsedConfigFile {
+	config := model.Config{}
+	assert.Nil(t, decodeConfig([]byte(raw), false, &config))
+	return parsedConfigFile{ConfigFile{Path: path, Import: isImport, Exists: true}, []byte(raw), config}
+}
+
+func TestValidateReferences(t *testing.T) {
+	t.Run("should accept a valid config", func(t *testing.T) {
+		main := parseTestFile(t, "sesh.toml", `
+blacklist = ["^scratch$"]
+sort_order = ["Config", "tmux"]
+
+[[session]]
+name = "dotfiles"
+path = "~/c/dotfiles"
+windows = ["git"]
+
+[[window]]
+name = "git"
+startup_script = "git pull"
+`, false)
+		errs := validateReferences([]parsedConfigFile{main})
+		assert.Empty(t, errs)
+	})
+
+	t.Run("should report problems with their position", func(t *testing.T) {
+		main := parseTestFile(t, "sesh.toml", `blacklist = ["("]
+
+[[session]]
+name = "dotfiles"
+path = "~/c/dotfiles"
+windows = ["git", "lazygit"]
+
+[[session]]
+name = "dotfiles"
+path = "~/dotfiles"
+
+[[window]]
+name = "git"
+`, false)
+		errs := validateReferences([]parsedConfigFile{main})
+		assert.Len(t, errs, 3)
+		assert.Equal(t, `sesh.toml:6: window "lazygit" used by session "dotfiles" is not defined`, errs[0].Error())
+		assert.Equal(t, `sesh.toml:9: session "dotfiles" is defined more than once`, errs[1].Error())
+		assert.Contains(t, errs[2].Error(), `sesh.toml:1: invalid blacklist pattern "("`)
+		human, ok := errs[0].(*ConfigError)
+		assert.True(t, ok)
+		assert.Equal(t, `6| windows = ["git", "lazygit"]`, human.Human())
+	})
+
+	t.Run("should report the position of the offending entry", func(t *testing.T) {
+		main := parseTestFile(t, "sesh.toml", `[[window]]
+name = "git"
+
+[[session]]
+name = "git"
+path = "~/c/git"
+windows = ["git"]
+
+[[session]]
+name = "notes"
+path = "~/notes"
+startup_command = [{ run = "nvim", wait_for = "(" }]
+
+[[session]]
+name = "blog"
+path = "~/c/blog"
+windows = ["git", "missing"]
+startup_command = "nvim"
+disable_startup_command = true
+`, false)
+		errs := validateReferences([]parsedConfigFile{main})
+		assert.Len(t, errs, 3)
+		assert.Contains(t, errs[0].Error(), `sesh.toml:12: invalid wait_for pattern "("`)
+		assert.Equal(t, `sesh.toml:19: session "blog" sets both startup_command and disable_startup_command`, errs[1].Error())
+		assert.Equal(t, `sesh.toml:17: window "missing" used by session "blog" is not defined`, errs[2].Error())
+	})
+
+	t.Run("should resolve references across imports", func(t *testing.T) {
+		main := parseTestFile(t, "sesh.toml", `import = ["work.toml"]
+
+[[session]]
+name = "dotfiles"
+path = "~/c/dotfiles"
+windows = ["git"]
+`, false)
+		imported := parseTestFile(t, "work.toml", `[[session]]
+name = "dotfiles"
+path = "~/work/dotfiles"
+
+[[window]]
+name = "git"
+startup_script = "git pull"
+`, true)
+		errs := validateReferences([]parsedConfigFile{main, imported})
+		assert.Empty(t, errs)
+	})
+
+	t.Run("should report invalid clone path templates", func(t *testing.T) {
+		main := parseTestFile(t, "sesh.toml", `[clone]
+path_template = "~/src/{{.Host}}/{{.Path}}"
+
+[clone.hosts]
+"gitlab.com" = "~/gl/{{.Namespace}}"
+"git.corp" = "~/work/{{.Path"
+`, false)
+		errs := validateReferences([]parsedConfigFile{main})
+		assert.Len(t, errs, 2)
+		assert.Contains(t, errs[0].Error(), `sesh.toml:6: invalid clone path template "~/work/{{.Path" for git.corp`)
+		assert.Contains(t, errs[1].Error(), `sesh.toml:5: invalid clone path template "~/gl/{{.Namespace}}" for gitlab.com`)
+	})
+
+	t.Run("should report invalid clone aliases", func(t *testing.T) {
+		main := parseTestFile(t, "sesh.toml", `[clone.aliases]
+gl = "git@gitlab.com:{{.Path}}.git"
+work = "{{.Path}}"
+`, false)
+		errs := validateReferences([]parsedConfigFile{main})
+		assert.Len(t, errs, 1)
+		assert.Contains(t, errs[0].Error(), `sesh.toml:3: invalid clone alias "{{.Path}}" for work`)
+	})
+
+	t.Run("should report post_clone hooks without commands or with invalid patterns", func(t *testing.T) {
+		main := parseTestFile(t, "sesh.toml", `[[post_clone]]
+name = "direnv"
+path = "~/work/**"
+commands = ["direnv allow"]
+
+[[post_clone]]
+name = "identity"
+host = "[git.corp"
+commands = ["git config user.email me@corp.example"]
+
+[[post_clone]]
+name = "empty"
+`, false)
+		errs := validateReferences([]parsedConfigFile{main})
+		assert.Len(t, errs, 2)
+		assert.Contains(t, errs[0].Error(), `sesh.toml:8: invalid host pattern "[git.corp" in post_clone hook identity`)
+		assert.Contains(t, errs[1].Error(), `sesh.toml:12: post_clone hook empty has no commands`)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	setup := func(files map[string]string) Configurator {
+		mockOs := new(oswrap.MockOs)
+		mockPath := new(pathwrap.MockPath)
+		mockOs.On("UserHomeDir").Return("/home/josh", nil)
+		mockOs.On("Getenv", mock.Anything).Return("")
+		mockOs.On("ReadFile", mock.Anything).Return(func(name string) ([]byte, error) {
+			if file, ok := files[name]; ok {
+				return []byte(file), nil
+			}
+			return nil, os.ErrNotExist
+		})
+		mockPath.On("Join", mock.Anything, mock.Anything, mock.Anything).Return(func(elem ...string) string { return path.Join(elem...) })
+		mockPath.On("Join", mock.Anything, mock.Anything).Return(func(elem ...string) string { return path.Join(elem...) })
+		return NewConfigurator(mockOs, mockPath, new(runtimewrap.MockRunTime), "")
+	}
+
+	t.Run("should accept a missing config file", func(t *testing.T) {
+		assert.Empty(t, setup(nil).Validate())
+	})
+
+	t.Run("should report a missing import file", func(t *testing.T) {
+		c := setup(map[string]string{
+			"/home/josh/.config/sesh/sesh.toml": `import = ["~/.config/sesh/work.toml"]`,
+		})
+		errs := c.Validate()
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "/home/josh/.config/sesh/work.toml: import file does not exist", errs[0].Error())
+	})
+}
@@ -0,0 +1,43 @@
+package connector
+
+import "github.com/joshmedeski/sesh/v2/model"
+
+// aliasStrategy connects to a clone alias like work:team/service, the
+// repository is cloned to its clone path first if it's missing
+func aliasStrategy(c *RealConnector, name string) (model.Connection, error) {
+	repoURL, isAlias, err := c.config.Clone.ExpandAlias(name)
+	if err != nil || !isAlias {
+		return model.Connection{Found: false}, err
+	}
+	remote, err := model.ParseGitURL(repoURL)
+	if err != nil {
+		return model.Connection{}, err
+	}
+	clonePath, err := c.config.Clone.ClonePath(remote, c.config.GitHub.CloneDir)
+	if err != nil {
+		return model.Connection{}, err
+	}
+	clonePath, err = c.home.ExpandHome(clonePath)
+	if err != nil {
+		return model.Connection{}, err
+	}
+
+	if err := cloneIfMissing(c, repoURL, clonePath); err != nil {
+		return model.Connection{}, err
+	}
+	nameFromPath, err := c.namer.Name(clonePath)
+	if err != nil {
+		return model.Connection{}, err
+	}
+	return model.Connection{
+		Found:       true,
+		New:         true,
+		AddToZoxide: true,
+		Bootstrap:   true,
+		Session: model.SeshSession{
+			Src:  "alias",
+			Name: nameFromPath,
+			Path: clonePath,
+		},
+	}, nil
+}
@@ -0,0 +1,59 @@
+package connector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/namer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAliasStrategy(t *testing.T) {
+	cloneDir := t.TempDir()
+	setup := func() (*git.MockGit, *RealConnector) {
+		mockGit := new(git.MockGit)
+		mockNamer := new(namer.MockNamer)
+		mockNamer.On("Name", mock.Anything).Return("service", nil)
+		mockHome := new(home.MockHome)
+		mockHome.On("ExpandHome", mock.Anything).Return(func(path string) (string, error) { return path, nil })
+		config := model.Config{Clone: model.CloneConfig{
+			PathTemplate: cloneDir + "/{{.Host}}/{{.Path}}",
+			Aliases:      map[string]string{"work": "ssh://git@git.corp:7999/{{.Path}}.git"},
+		}}
+		return mockGit, &RealConnector{config: config, git: mockGit, home: mockHome, namer: mockNamer}
+	}
+	clonePath := filepath.Join(cloneDir, "git.corp", "team", "service")
+
+	t.Run("should clone a missing repository", func(t *testing.T) {
+		mockGit, c := setup()
+		mockGit.On("Clone", "ssh://git@git.corp:7999/team/service.git", filepath.Dir(clonePath), "service", model.GitCloneFlags{}).Return("", nil)
+		connection, err := aliasStrategy(c, "work:team/service")
+		assert.Nil(t, err)
+		assert.True(t, connection.Found)
+		assert.Equal(t, clonePath, connection.Session.Path)
+		assert.Equal(t, "service", connection.Session.Name)
+		assert.True(t, connection.Bootstrap)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("should connect to an existing checkout", func(t *testing.T) {
+		mockGit, c := setup()
+		assert.Nil(t, os.MkdirAll(clonePath, 0755))
+		connection, err := aliasStrategy(c, "work:team/service")
+		assert.Nil(t, err)
+		assert.Equal(t, clonePath, connection.Session.Path)
+		mockGit.AssertNotCalled(t, "Clone", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should skip other names", func(t *testing.T) {
+		_, c := setup()
+		connection, err := aliasStrategy(c, "team/service")
+		assert.Nil(t, err)
+		assert.False(t, connection.Found)
+	})
+}
@@ -17,6 +17,8 @@ func (c *RealConnector) Connect(name string, opts model.ConnectOpts) (string, er
 		tmuxinatorStrategy,
 		configStrategy,
 		githubStrategy,
+		gitlabStrategy,
+		aliasStrategy,
 		dirStrategy,
 		zoxideStrategy,
 	}
@@ -26,6 +28,8 @@ func (c *RealConnector) Connect(name string, opts model.ConnectOpts) (string, er
 		"tmuxinator": connectToTmuxinator,
 		"config":     connectToTmux,
 		"github":     connectToTmux,
+		"gitlab":     connectToTmux,
+		"alias":      connectToTmux,
 		"dir":        connectToTmux,
 		"zoxide":     connectToTmux,
 	}
@@ -39,6 +43,11 @@ func (c *RealConnector) Connect(name string, opts model.ConnectOpts) (string, er
 			if connection.AddToZoxide {
 				c.zoxide.Add(connection.Session.Path)
 			}
+			if connection.Bootstrap && !opts.NoHooks {
+				if err := c.hooks.PostClone(connection.Session.Path, false); err != nil {
+					return "", err
+				}
+			}
 			return connectStrategy[connection.Session.Src](c, connection, opts)
 		}
 	}
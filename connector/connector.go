@@ -4,6 +4,7 @@ import (
 	"github.com/joshmedeski/sesh/v2/dir"
 	"github.com/joshmedeski/sesh/v2/git"
 	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/hooks"
 	"github.com/joshmedeski/sesh/v2/lister"
 	"github.com/joshmedeski/sesh/v2/model"
 	"github.com/joshmedeski/sesh/v2/namer"
@@ -28,6 +29,7 @@ type RealConnector struct {
 	tmux       tmux.Tmux
 	zoxide     zoxide.Zoxide
 	tmuxinator tmuxinator.Tmuxinator
+	hooks      hooks.Hooks
 }
 
 func NewConnector(
@@ -41,6 +43,7 @@ func NewConnector(
 	tmux tmux.Tmux,
 	zoxide zoxide.Zoxide,
 	tmuxinator tmuxinator.Tmuxinator,
+	hooks hooks.Hooks,
 ) Connector {
 	return &RealConnector{
 		config,
@@ -53,5 +56,6 @@ func NewConnector(
 		tmux,
 		zoxide,
 		tmuxinator,
+		hooks,
 	}
 }
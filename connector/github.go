@@ -14,41 +14,61 @@ func githubStrategy(c *RealConnector, name string) (model.Connection, error) {
 	if !exists {
 		return model.Connection{Found: false}, nil
 	}
-	
+
+	session, cloned, err := cloneOnConnect(c, session)
+	if err != nil {
+		return model.Connection{}, err
+	}
+
+	return model.Connection{
+		Found:       true,
+		Session:     session,
+		New:         true, // GitHub sessions are always "new" since they create tmux sessions
+		AddToZoxide: true,
+		Bootstrap:   cloned,
+	}, nil
+}
+
+// cloneOnConnect clones a repository listed with a clone startup command and
+// points the session at the checkout, it reports whether the session is such
+// a clone
+func cloneOnConnect(c *RealConnector, session model.SeshSession) (model.SeshSession, bool, error) {
 	// Check if this is an uncloned repository that needs cloning
-	if session.StartupCommand != "" && strings.Contains(session.StartupCommand, "git clone") {
+	if startupCommand := session.StartupCommand.String(); strings.Contains(startupCommand, "git clone") {
 		// Extract clone information from the startup command
 		// The startup command format is: "git clone <url> <path> && cd <path>"
-		parts := strings.Split(session.StartupCommand, " ")
-		
+		parts := strings.Split(startupCommand, " ")
+
 		if len(parts) >= 4 && parts[0] == "git" && parts[1] == "clone" {
 			repoURL := parts[2]
 			clonePath := parts[3]
-			
-			// Create parent directory if it doesn't exist
-			parentDir := filepath.Dir(clonePath)
-			if err := os.MkdirAll(parentDir, 0755); err != nil {
-				return model.Connection{}, fmt.Errorf("failed to create parent directory: %w", err)
-			}
-			
-			// Check if already cloned
-			if _, err := os.Stat(clonePath); os.IsNotExist(err) {
-				// Clone the repository using git directly
-				if _, err := c.git.Clone(repoURL, parentDir, filepath.Base(clonePath)); err != nil {
-					return model.Connection{}, fmt.Errorf("failed to clone repository: %w", err)
-				}
+			if err := cloneIfMissing(c, repoURL, clonePath); err != nil {
+				return model.SeshSession{}, false, err
 			}
-			
+
 			// Update session to point to the cloned directory and remove startup command
 			session.Path = clonePath
-			session.StartupCommand = ""
+			session.StartupCommand = nil
+			return session, true, nil
 		}
 	}
-	
-	return model.Connection{
-		Found:       true,
-		Session:     session,
-		New:         true, // GitHub sessions are always "new" since they create tmux sessions
-		AddToZoxide: true,
-	}, nil
+	return session, false, nil
+}
+
+// cloneIfMissing clones a repository unless its clone path exists
+func cloneIfMissing(c *RealConnector, repoURL, clonePath string) error {
+	// Create parent directory if it doesn't exist
+	parentDir := filepath.Dir(clonePath)
+	if err := os.MkdirAll(parentDir, 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	// Check if already cloned
+	if _, err := os.Stat(clonePath); os.IsNotExist(err) {
+		// Clone the repository using git directly
+		if _, err := c.git.Clone(repoURL, parentDir, filepath.Base(clonePath), c.config.Clone.Flags()); err != nil {
+			return fmt.Errorf("failed to clone repository: %w", err)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,23 @@
+package connector
+
+import "github.com/joshmedeski/sesh/v2/model"
+
+func gitlabStrategy(c *RealConnector, name string) (model.Connection, error) {
+	session, exists := c.lister.FindGitLabSession(name)
+	if !exists {
+		return model.Connection{Found: false}, nil
+	}
+
+	session, cloned, err := cloneOnConnect(c, session)
+	if err != nil {
+		return model.Connection{}, err
+	}
+
+	return model.Connection{
+		Found:       true,
+		Session:     session,
+		New:         true,
+		AddToZoxide: true,
+		Bootstrap:   cloned,
+	}, nil
+}
@@ -1,6 +1,11 @@
 package connector
 
-import "github.com/joshmedeski/sesh/v2/model"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joshmedeski/sesh/v2/model"
+)
 
 func tmuxStrategy(c *RealConnector, name string) (model.Connection, error) {
 	session, exists := c.lister.FindTmuxSession(name)
@@ -16,9 +21,24 @@ func tmuxStrategy(c *RealConnector, name string) (model.Connection, error) {
 }
 
 func connectToTmux(c *RealConnector, connection model.Connection, opts model.ConnectOpts) (string, error) {
+	var progress string
+	var startupErr error
 	if connection.New {
-		c.tmux.NewSession(connection.Session.Name, connection.Session.Path)
-		c.startup.Exec(connection.Session)
+		env, err := c.startup.Env(connection.Session)
+		if err != nil {
+			return "", fmt.Errorf("couldn't match rules: %w", err)
+		}
+		c.tmux.NewSession(connection.Session.Name, connection.Session.Path, env)
+		progress, startupErr = c.startup.Exec(connection.Session)
+	}
+
+	// Connect even when the startup command failed, so the session can be fixed
+	out, err := c.tmux.SwitchOrAttach(connection.Session.Name, opts)
+	if err != nil {
+		return out, err
+	}
+	if startupErr != nil {
+		return progress, fmt.Errorf("connected to %s, but the startup command failed: %w", connection.Session.Name, startupErr)
 	}
-	return c.tmux.SwitchOrAttach(connection.Session.Name, opts)
+	return strings.TrimSpace(strings.Join([]string{progress, out}, "\n")), nil
 }
@@ -1,13 +1,29 @@
 package git
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/joshmedeski/sesh/v2/model"
 	"github.com/joshmedeski/sesh/v2/shell"
 )
 
 type Git interface {
 	ShowTopLevel(name string) (bool, string, error)
 	GitCommonDir(name string) (bool, string, error)
-	Clone(url string, cmdDir string, dir string) (string, error)
+	Clone(url string, cmdDir string, dir string, flags model.GitCloneFlags) (string, error)
+	// CloneBareWorktree clones into <dir>/.bare and checks a branch out in the
+	// worktree <dir>/<branch>, it returns the branch
+	CloneBareWorktree(url string, cmdDir string, dir string, flags model.GitCloneFlags) (string, error)
+	CurrentBranch(path string) (string, error)
+	RemoteURL(path string) (string, error)
+	Fetch(path string, remote string, refspec string) (string, error)
+	WorktreeAdd(path string, worktreePath string, branch string, commitish string) (string, error)
+	IsDirty(path string) (bool, error)
+	ResetHard(path string, commitish string) (string, error)
 }
 
 type RealGit struct {
@@ -34,11 +50,13 @@ func (g *RealGit) GitCommonDir(path string) (bool, string, error) {
 	return true, out, nil
 }
 
-func (g *RealGit) Clone(url string, cmdDir string, dir string) (string, error) {
-	var out string
-	var err error
-
-	args := []string{"clone", url}
+// Clone streams the progress of git clone unless the flags are quiet
+func (g *RealGit) Clone(url string, cmdDir string, dir string, flags model.GitCloneFlags) (string, error) {
+	args := append([]string{"clone"}, cloneArgs(flags)...)
+	if flags.RecurseSubmodules {
+		args = append(args, "--recurse-submodules")
+	}
+	args = append(args, url)
 	if cmdDir != "" {
 		args = append([]string{"-C", cmdDir}, args...)
 	}
@@ -46,9 +64,111 @@ func (g *RealGit) Clone(url string, cmdDir string, dir string) (string, error) {
 		args = append(args, dir)
 	}
 
-	out, err = g.shell.Cmd("git", args...)
-	if err != nil {
+	return "", g.run(flags.Quiet, "Cloning "+url, args...)
+}
+
+func (g *RealGit) CloneBareWorktree(url string, cmdDir string, dir string, flags model.GitCloneFlags) (string, error) {
+	if dir == "" {
+		dir = strings.TrimSuffix(filepath.Base(url), ".git")
+	}
+	root := filepath.Join(cmdDir, dir)
+	bare := filepath.Join(root, ".bare")
+
+	args := append([]string{"clone", "--bare"}, cloneArgs(flags)...)
+	if err := g.run(flags.Quiet, "Cloning "+url, append(args, url, bare)...); err != nil {
 		return "", err
 	}
-	return out, nil
+
+	// The .git file lets git commands find the bare repository from the root
+	if err := os.WriteFile(filepath.Join(root, ".git"), []byte("gitdir: ./.bare\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to write .git file: %w", err)
+	}
+	// Bare clones don't track the remote branches
+	if _, err := g.shell.Cmd("git", "-C", bare, "config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*"); err != nil {
+		return "", err
+	}
+
+	branch := flags.Branch
+	if branch == "" {
+		head, err := g.shell.Cmd("git", "-C", bare, "symbolic-ref", "--short", "HEAD")
+		if err != nil {
+			return "", err
+		}
+		branch = head
+	}
+	worktree := filepath.Join(root, branch)
+	if _, err := g.shell.Cmd("git", "-C", root, "worktree", "add", worktree, branch); err != nil {
+		return "", err
+	}
+	if flags.RecurseSubmodules {
+		if err := g.run(flags.Quiet, "Updating submodules", "-C", worktree, "submodule", "update", "--init", "--recursive"); err != nil {
+			return "", err
+		}
+	}
+	return branch, nil
+}
+
+// run streams the progress of a long git command unless it's quiet
+func (g *RealGit) run(quiet bool, title string, args ...string) error {
+	if quiet {
+		_, err := g.shell.Cmd("git", args...)
+		return err
+	}
+	return g.shell.Stream(title, "git", args...)
+}
+
+// cloneArgs are the flags of git clone that apply to bare clones too
+func cloneArgs(flags model.GitCloneFlags) []string {
+	var args []string
+	if flags.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(flags.Depth))
+	}
+	if flags.Branch != "" {
+		args = append(args, "--branch", flags.Branch)
+	}
+	if flags.Filter != "" {
+		args = append(args, "--filter="+flags.Filter)
+	}
+	if flags.Quiet {
+		args = append(args, "--quiet")
+	}
+	return args
+}
+
+func (g *RealGit) CurrentBranch(path string) (string, error) {
+	return g.shell.Cmd("git", "-C", path, "rev-parse", "--abbrev-ref", "HEAD")
+}
+
+func (g *RealGit) RemoteURL(path string) (string, error) {
+	return g.shell.Cmd("git", "-C", path, "remote", "get-url", "origin")
+}
+
+// Fetch fetches a refspec from remote, or the configured refspecs when it's
+// empty
+func (g *RealGit) Fetch(path string, remote string, refspec string) (string, error) {
+	args := []string{"-C", path, "fetch", remote}
+	if refspec != "" {
+		args = append(args, refspec)
+	}
+	return g.shell.Cmd("git", args...)
+}
+
+// WorktreeAdd checks out commitish on branch in a new worktree, the branch is
+// reset when it already exists
+func (g *RealGit) WorktreeAdd(path string, worktreePath string, branch string, commitish string) (string, error) {
+	return g.shell.Cmd("git", "-C", path, "worktree", "add", "-B", branch, worktreePath, commitish)
+}
+
+// IsDirty reports whether the worktree has uncommitted or untracked changes
+func (g *RealGit) IsDirty(path string) (bool, error) {
+	out, err := g.shell.Cmd("git", "-C", path, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// ResetHard moves the checked out branch to commitish and discards changes
+func (g *RealGit) ResetHard(path string, commitish string) (string, error) {
+	return g.shell.Cmd("git", "-C", path, "reset", "--hard", commitish)
 }
@@ -5,99 +5,102 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
-	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/cache"
 	"github.com/joshmedeski/sesh/v2/model"
 )
 
+// CacheSource is the source of GitHub listings in the sesh cache
+const CacheSource = "github"
+
 type Cache interface {
 	Get(org string) ([]model.GitHubRepo, bool)
 	Set(org string, repos []model.GitHubRepo, timeout int)
+	// Lookup returns the cached entry even when it has expired
+	Lookup(org string) (model.GitHubCache, bool)
+	SetWithValidators(org string, repos []model.GitHubRepo, validators model.CacheValidators, timeout int)
 	GetCachePath() string
 }
 
 type RealCache struct {
-	home home.Home
+	store cache.Cache
 }
 
-func NewCache(home home.Home) Cache {
+func NewCache(store cache.Cache) Cache {
 	return &RealCache{
-		home: home,
+		store: store,
 	}
 }
 
-func (c *RealCache) Get(org string) ([]model.GitHubRepo, bool) {
-	cachePath := c.getCacheFilePath(org)
-	
-	if _, err := os.Stat(cachePath); os.IsNotExist(err) {
-		return nil, false
-	}
-
-	data, err := os.ReadFile(cachePath)
-	if err != nil {
-		slog.Warn("Failed to read cache file", "path", cachePath, "error", err)
-		return nil, false
-	}
+// cachedRepos is the value cached per organization
+type cachedRepos struct {
+	Repos []model.GitHubRepo `json:"repos"`
+	model.CacheValidators
+}
 
-	var cache model.GitHubCache
-	if err := json.Unmarshal(data, &cache); err != nil {
-		slog.Warn("Failed to unmarshal cache", "error", err)
+func (c *RealCache) Get(org string) ([]model.GitHubRepo, bool) {
+	cached, found := c.Lookup(org)
+	if !found {
 		return nil, false
 	}
 
-	if time.Now().After(cache.ExpiresAt) {
-		slog.Debug("Cache expired", "expired_at", cache.ExpiresAt)
+	if time.Now().After(cached.ExpiresAt) {
+		slog.Debug("Cache expired", "expired_at", cached.ExpiresAt)
 		return nil, false
 	}
 
-	slog.Debug("Cache hit", "org", org, "repos_count", len(cache.Repos))
-	return cache.Repos, true
+	slog.Debug("Cache hit", "org", org, "repos_count", len(cached.Repos))
+	return cached.Repos, true
 }
 
-func (c *RealCache) Set(org string, repos []model.GitHubRepo, timeout int) {
-	cachePath := c.getCacheFilePath(org)
-	
-	// Ensure cache directory exists
-	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
-		slog.Error("Failed to create cache directory", "error", err)
-		return
+func (c *RealCache) Lookup(org string) (model.GitHubCache, bool) {
+	var value cachedRepos
+	entry, found := c.store.Get(CacheSource, org, &value)
+	if !found {
+		return c.legacy(org)
 	}
+	return model.GitHubCache{
+		Repos:           value.Repos,
+		CachedAt:        entry.CachedAt,
+		ExpiresAt:       entry.ExpiresAt,
+		CacheValidators: value.CacheValidators,
+	}, true
+}
 
-	now := time.Now()
-	cache := model.GitHubCache{
-		Repos:     repos,
-		CachedAt:  now,
-		ExpiresAt: now.Add(time.Duration(timeout) * time.Minute),
+// legacy reads the repos of an organization or user cached by sesh before the
+// shared cache store, they're at the same path without the store's envelope.
+// The next Set replaces the file.
+func (c *RealCache) legacy(org string) (model.GitHubCache, bool) {
+	if org == "" || strings.ContainsAny(org, "/@\\") {
+		return model.GitHubCache{}, false
 	}
-
-	data, err := json.MarshalIndent(cache, "", "  ")
+	data, err := os.ReadFile(filepath.Join(c.store.Dir(CacheSource), org+".json"))
 	if err != nil {
-		slog.Error("Failed to marshal cache", "error", err)
-		return
+		return model.GitHubCache{}, false
 	}
-
-	if err := os.WriteFile(cachePath, data, 0644); err != nil {
-		slog.Error("Failed to write cache file", "path", cachePath, "error", err)
-		return
+	var cached model.GitHubCache
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Repos == nil {
+		return model.GitHubCache{}, false
 	}
-
-	slog.Debug("Cache updated", "org", org, "repos_count", len(repos), "expires_at", cache.ExpiresAt)
+	slog.Debug("Legacy cache hit", "org", org, "repos_count", len(cached.Repos))
+	return cached, true
 }
 
-func (c *RealCache) GetCachePath() string {
-	return c.getCacheFilePath("")
+func (c *RealCache) Set(org string, repos []model.GitHubRepo, timeout int) {
+	c.SetWithValidators(org, repos, model.CacheValidators{}, timeout)
 }
 
-func (c *RealCache) getCacheFilePath(org string) string {
-	// Get home directory using os package since Home interface doesn't expose HomeDir
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		slog.Error("Failed to get home directory", "error", err)
-		return ""
-	}
-	if org == "" {
-		return filepath.Join(homeDir, ".cache", "sesh", "github")
+func (c *RealCache) SetWithValidators(org string, repos []model.GitHubRepo, validators model.CacheValidators, timeout int) {
+	value := cachedRepos{Repos: repos, CacheValidators: validators}
+	if err := c.store.Set(CacheSource, org, value, time.Duration(timeout)*time.Minute); err != nil {
+		slog.Error("Failed to write cache", "org", org, "error", err)
+		return
 	}
-	return filepath.Join(homeDir, ".cache", "sesh", "github", org+".json")
+	slog.Debug("Cache updated", "org", org, "repos_count", len(repos))
+}
+
+func (c *RealCache) GetCachePath() string {
+	return c.store.Dir(CacheSource)
 }
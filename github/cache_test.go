@@ -0,0 +1,54 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/cache"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	newTestCache := func(t *testing.T) Cache {
+		t.Setenv("HOME", t.TempDir())
+		return NewCache(cache.NewCache(home.NewHome(oswrap.NewOs())))
+	}
+	repos := []model.GitHubRepo{{Name: "dotfiles", FullName: "josh/dotfiles"}}
+
+	t.Run("should read the cache of older versions", func(t *testing.T) {
+		c := newTestCache(t)
+		legacy := `{"repos":[{"name":"dotfiles","full_name":"josh/dotfiles"}],"expires_at":"2099-01-01T00:00:00Z"}`
+		assert.NoError(t, os.MkdirAll(c.GetCachePath(), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(c.GetCachePath(), "josh.json"), []byte(legacy), 0o644))
+
+		cached, found := c.Lookup("josh")
+		assert.True(t, found)
+		assert.Equal(t, "dotfiles", cached.Repos[0].Name)
+		got, found := c.Get("josh")
+		assert.True(t, found)
+		assert.Len(t, got, 1)
+	})
+
+	t.Run("should prefer the cache store", func(t *testing.T) {
+		c := newTestCache(t)
+		c.Set("josh", repos, 60)
+
+		cached, found := c.Lookup("josh")
+		assert.True(t, found)
+		assert.Equal(t, repos, cached.Repos)
+		assert.True(t, cached.ExpiresAt.After(time.Now()))
+	})
+
+	t.Run("should not read other files", func(t *testing.T) {
+		c := newTestCache(t)
+		_, found := c.Lookup(model.PersonalCacheKey)
+		assert.False(t, found)
+		_, found = c.Lookup("github.example.com/acme")
+		assert.False(t, found)
+	})
+}
@@ -2,8 +2,14 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/google/go-github/v66/github"
 	"github.com/joshmedeski/sesh/v2/model"
@@ -18,39 +24,137 @@ type Client interface {
 	ListUserReposWithToken(username, token string) ([]model.GitHubRepo, error)
 	ListAuthenticatedUserReposWithToken(token string) ([]model.GitHubRepo, error)
 	GetAuthenticatedUsername(token string) (string, error)
+	// WithBaseURL returns a client for the API of a GitHub Enterprise Server,
+	// an empty base url is api.github.com
+	WithBaseURL(baseURL string) Client
+	// The conditional listings return ErrNotModified when the repositories
+	// haven't changed since the response the validators came from
+	ListOrgReposConditional(org, token string, validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error)
+	ListUserReposConditional(username, token string, validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error)
+	ListAuthenticatedUserReposConditional(token string, validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error)
+	ListStarredReposConditional(token string, validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error)
+	ListTeamReposConditional(org, teamSlug, token string, validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error)
+	ListCollaboratorReposConditional(token string, validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error)
+	ListPullRequests(org, repo, token string) ([]model.GitHubPullRequest, error)
+	// ListReposGraphQL lists the repositories of several owners, and those of
+	// the viewer, with batched GraphQL queries
+	ListReposGraphQL(owners []string, viewer bool, token string) (map[string][]model.GitHubRepo, error)
 }
 
+// ErrNotModified is returned by conditional requests when the cached response
+// is still current, GitHub doesn't count it against the rate limit
+var ErrNotModified = errors.New("not modified")
+
 // RealClient wraps the go-github client
 type RealClient struct {
 	defaultToken string
+	baseURL      string
+	rateLimits   RateLimits
 }
 
 // NewClient creates a new GitHub client
-func NewClient(token string) Client {
+func NewClient(token string, rateLimits RateLimits) Client {
 	return &RealClient{
 		defaultToken: token,
+		rateLimits:   rateLimits,
 	}
 }
 
-// createGitHubClient creates a go-github client with the given token
-func (c *RealClient) createGitHubClient(token string) *github.Client {
-	if token == "" {
-		// Try to get token from environment if not provided
-		token = os.Getenv("GITHUB_TOKEN")
+// WithBaseURL returns a copy of the client that targets the given API url,
+// the default token is a github.com token and isn't sent to other hosts
+func (c *RealClient) WithBaseURL(baseURL string) Client {
+	client := &RealClient{
+		baseURL:    baseURL,
+		rateLimits: c.rateLimits,
+	}
+	if baseURL == "" {
+		client.defaultToken = c.defaultToken
+	}
+	return client
+}
+
+// resolveToken falls back to $GITHUB_TOKEN on github.com, it's never sent to
+// a GitHub Enterprise Server
+func (c *RealClient) resolveToken(token string) string {
+	if token == "" && c.baseURL == "" {
+		return os.Getenv("GITHUB_TOKEN")
+	}
+	return token
+}
+
+// host is the GitHub host rate limits are recorded for
+func (c *RealClient) host() string {
+	if c.baseURL == "" {
+		return "github.com"
+	}
+	if u, err := url.Parse(c.baseURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return c.baseURL
+}
+
+// createGitHubClient creates a go-github client with the given token, it fails
+// with a RateLimitError while the token's rate limit is exhausted
+func (c *RealClient) createGitHubClient(token string) (*github.Client, error) {
+	token = c.resolveToken(token)
+	if limit, found := c.rateLimits.Get(c.host(), token); found {
+		if until, limited := limit.Limited(time.Now()); limited {
+			slog.Debug("Skipping GitHub request until the rate limit resets", "host", limit.Host, "until", until)
+			return nil, &RateLimitError{RateLimit: limit, Until: until, Secondary: until.Equal(limit.RetryAfter)}
+		}
 	}
 	
+	var client *github.Client
 	if token == "" {
-		// Return unauthenticated client (rate limited)
-		return github.NewClient(nil)
+		// Unauthenticated client (rate limited)
+		client = github.NewClient(nil)
+	} else {
+		// Create OAuth2 token source
+		ts := oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: token},
+		)
+		tc := oauth2.NewClient(context.Background(), ts)
+		client = github.NewClient(tc)
 	}
 
-	// Create OAuth2 token source
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-	tc := oauth2.NewClient(context.Background(), ts)
-	
-	return github.NewClient(tc)
+	if c.baseURL == "" {
+		return client, nil
+	}
+	client, err := client.WithEnterpriseURLs(c.baseURL, c.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub base url %s: %w", c.baseURL, err)
+	}
+	return client, nil
+}
+
+// observe records the rate limit of a response and converts its error
+func (c *RealClient) observe(token string, resp *github.Response, err error) error {
+	token = c.resolveToken(token)
+	limit, _ := c.rateLimits.Get(c.host(), token)
+	limit.Host = c.host()
+	limit.Token = TokenID(token)
+	if resp != nil && resp.Rate.Limit > 0 {
+		limit.Limit = resp.Rate.Limit
+		limit.Remaining = resp.Rate.Remaining
+		limit.Reset = resp.Rate.Reset.Time
+		limit.CheckedAt = time.Now()
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		retryAfter := time.Minute
+		if abuseErr.RetryAfter != nil {
+			retryAfter = *abuseErr.RetryAfter
+		}
+		limit.RetryAfter = time.Now().Add(retryAfter)
+		limit.CheckedAt = time.Now()
+	}
+	if !limit.CheckedAt.IsZero() {
+		c.rateLimits.Set(limit)
+	}
+	if err == nil {
+		return nil
+	}
+	return convertError(err, limit)
 }
 
 // convertRepo converts a go-github repository to our model
@@ -94,37 +198,23 @@ func (c *RealClient) ListOrgRepos(org string) ([]model.GitHubRepo, error) {
 
 // ListOrgReposWithToken lists repositories for an organization with a specific token
 func (c *RealClient) ListOrgReposWithToken(org, token string) ([]model.GitHubRepo, error) {
-	client := c.createGitHubClient(token)
-	ctx := context.Background()
-	
-	var allRepos []model.GitHubRepo
-	
-	opts := &github.RepositoryListByOrgOptions{
-		Type: "all", // public, private, forks, sources, member
-		Sort: "updated",
-		Direction: "desc",
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
-	}
-
-	for {
-		repos, resp, err := client.Repositories.ListByOrg(ctx, org, opts)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list repositories for org %s: %w", org, err)
-		}
-
-		for _, repo := range repos {
-			allRepos = append(allRepos, convertRepo(repo))
-		}
+	repos, _, err := c.ListOrgReposConditional(org, token, model.CacheValidators{})
+	return repos, err
+}
 
-		if resp.NextPage == 0 {
-			break
-		}
-		opts.Page = resp.NextPage
+// ListOrgReposConditional lists repositories for an organization unless they
+// haven't changed since the response the validators came from
+func (c *RealClient) ListOrgReposConditional(org, token string, validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error) {
+	client, err := c.createGitHubClient(token)
+	if err != nil {
+		return nil, model.CacheValidators{}, err
 	}
-
-	return allRepos, nil
+	query := url.Values{"type": {"all"}, "sort": {"updated"}, "direction": {"desc"}}
+	repos, latest, err := c.listPages(client, token, fmt.Sprintf("orgs/%s/repos", org), query, validators)
+	if err != nil && !errors.Is(err, ErrNotModified) {
+		return nil, model.CacheValidators{}, fmt.Errorf("failed to list repositories for org %s: %w", org, err)
+	}
+	return repos, latest, err
 }
 
 // ListUserRepos lists repositories for a user using the default token
@@ -134,124 +224,219 @@ func (c *RealClient) ListUserRepos(username string) ([]model.GitHubRepo, error)
 
 // ListAuthenticatedUserReposWithToken lists repositories for the authenticated user
 func (c *RealClient) ListAuthenticatedUserReposWithToken(token string) ([]model.GitHubRepo, error) {
-	client := c.createGitHubClient(token)
-	ctx := context.Background()
-	
-	var allRepos []model.GitHubRepo
-	
-	opts := &github.RepositoryListOptions{
-		Affiliation: "owner",
-		Sort:        "updated",
-		Direction:   "desc",
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
-	}
-
-	for {
-		repos, resp, err := client.Repositories.List(ctx, "", opts)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list repositories for authenticated user: %w", err)
-		}
-
-		for _, repo := range repos {
-			allRepos = append(allRepos, convertRepo(repo))
-		}
+	repos, _, err := c.ListAuthenticatedUserReposConditional(token, model.CacheValidators{})
+	return repos, err
+}
 
-		if resp.NextPage == 0 {
-			break
-		}
-		opts.Page = resp.NextPage
+// ListAuthenticatedUserReposConditional lists repositories for the
+// authenticated user unless they haven't changed since the validators
+func (c *RealClient) ListAuthenticatedUserReposConditional(token string, validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error) {
+	client, err := c.createGitHubClient(token)
+	if err != nil {
+		return nil, model.CacheValidators{}, err
 	}
-
-	return allRepos, nil
+	query := url.Values{"affiliation": {"owner"}, "sort": {"updated"}, "direction": {"desc"}}
+	repos, latest, err := c.listPages(client, token, "user/repos", query, validators)
+	if err != nil && !errors.Is(err, ErrNotModified) {
+		return nil, model.CacheValidators{}, fmt.Errorf("failed to list repositories for authenticated user: %w", err)
+	}
+	return repos, latest, err
 }
 
 // GetAuthenticatedUsername returns the username of the authenticated user
 func (c *RealClient) GetAuthenticatedUsername(token string) (string, error) {
-	client := c.createGitHubClient(token)
-	ctx := context.Background()
-	
-	user, _, err := client.Users.Get(ctx, "")
+	client, err := c.createGitHubClient(token)
 	if err != nil {
+		return "", err
+	}
+	ctx := context.Background()
+
+	user, resp, err := client.Users.Get(ctx, "")
+	if err := c.observe(token, resp, err); err != nil {
 		return "", fmt.Errorf("failed to get authenticated user: %w", err)
 	}
-	
+
 	if user.Login == nil {
 		return "", fmt.Errorf("authenticated user login is nil")
 	}
-	
+
 	return *user.Login, nil
 }
 
 // ListUserReposWithToken lists repositories for a user with a specific token
 func (c *RealClient) ListUserReposWithToken(username, token string) ([]model.GitHubRepo, error) {
-	client := c.createGitHubClient(token)
-	ctx := context.Background()
-	
-	var allRepos []model.GitHubRepo
-	
+	repos, _, err := c.ListUserReposConditional(username, token, model.CacheValidators{})
+	return repos, err
+}
+
+// ListUserReposConditional lists repositories for a user unless they haven't
+// changed since the validators, private repositories are included when the
+// token belongs to the user
+func (c *RealClient) ListUserReposConditional(username, token string, validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error) {
+	client, err := c.createGitHubClient(token)
+	if err != nil {
+		return nil, model.CacheValidators{}, err
+	}
+
 	// First, try to get the authenticated user to see if this is their own profile
-	var isAuthenticatedUser bool
 	if token != "" {
-		if user, _, err := client.Users.Get(ctx, ""); err == nil && user.Login != nil && *user.Login == username {
-			isAuthenticatedUser = true
+		user, resp, err := client.Users.Get(context.Background(), "")
+		if err := c.observe(token, resp, err); errors.Is(err, ErrRateLimited) {
+			return nil, model.CacheValidators{}, fmt.Errorf("failed to list repositories for user %s: %w", username, err)
+		}
+		if err == nil && user.Login != nil && *user.Login == username {
+			// Use authenticated user endpoint to get private repos
+			query := url.Values{"affiliation": {"owner"}, "sort": {"updated"}, "direction": {"desc"}}
+			repos, latest, err := c.listPages(client, token, "user/repos", query, validators)
+			if err != nil && !errors.Is(err, ErrNotModified) {
+				return nil, model.CacheValidators{}, fmt.Errorf("failed to list repositories for authenticated user %s: %w", username, err)
+			}
+			return repos, latest, err
 		}
 	}
 
-	if isAuthenticatedUser {
-		// Use authenticated user endpoint to get private repos
-		opts := &github.RepositoryListOptions{
-			Affiliation: "owner",
-			Sort:        "updated",
-			Direction:   "desc",
-			ListOptions: github.ListOptions{
-				PerPage: 100,
-			},
-		}
+	// Use public user endpoint (only public repos)
+	query := url.Values{"type": {"all"}, "sort": {"updated"}, "direction": {"desc"}}
+	repos, latest, err := c.listPages(client, token, fmt.Sprintf("users/%s/repos", username), query, validators)
+	if err != nil && !errors.Is(err, ErrNotModified) {
+		return nil, model.CacheValidators{}, fmt.Errorf("failed to list repositories for user %s: %w", username, err)
+	}
+	return repos, latest, err
+}
 
-		for {
-			repos, resp, err := client.Repositories.List(ctx, "", opts)
-			if err != nil {
-				return nil, fmt.Errorf("failed to list repositories for authenticated user %s: %w", username, err)
-			}
+// ListStarredReposConditional lists the repositories starred by the
+// authenticated user unless they haven't changed since the validators
+func (c *RealClient) ListStarredReposConditional(token string, validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error) {
+	client, err := c.createGitHubClient(token)
+	if err != nil {
+		return nil, model.CacheValidators{}, err
+	}
+	query := url.Values{"sort": {"updated"}, "direction": {"desc"}}
+	repos, latest, err := c.listPages(client, token, "user/starred", query, validators)
+	if err != nil && !errors.Is(err, ErrNotModified) {
+		return nil, model.CacheValidators{}, fmt.Errorf("failed to list starred repositories: %w", err)
+	}
+	return repos, latest, err
+}
 
-			for _, repo := range repos {
-				allRepos = append(allRepos, convertRepo(repo))
-			}
+// ListTeamReposConditional lists the repositories of a team unless they
+// haven't changed since the validators
+func (c *RealClient) ListTeamReposConditional(org, teamSlug, token string, validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error) {
+	client, err := c.createGitHubClient(token)
+	if err != nil {
+		return nil, model.CacheValidators{}, err
+	}
+	repos, latest, err := c.listPages(client, token, fmt.Sprintf("orgs/%s/teams/%s/repos", org, teamSlug), url.Values{}, validators)
+	if err != nil && !errors.Is(err, ErrNotModified) {
+		return nil, model.CacheValidators{}, fmt.Errorf("failed to list repositories for team %s/%s: %w", org, teamSlug, err)
+	}
+	return repos, latest, err
+}
 
-			if resp.NextPage == 0 {
-				break
-			}
-			opts.Page = resp.NextPage
+// ListCollaboratorReposConditional lists the repositories the authenticated
+// user is a collaborator on unless they haven't changed since the validators
+func (c *RealClient) ListCollaboratorReposConditional(token string, validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error) {
+	client, err := c.createGitHubClient(token)
+	if err != nil {
+		return nil, model.CacheValidators{}, err
+	}
+	query := url.Values{"affiliation": {"collaborator"}, "sort": {"updated"}, "direction": {"desc"}}
+	repos, latest, err := c.listPages(client, token, "user/repos", query, validators)
+	if err != nil && !errors.Is(err, ErrNotModified) {
+		return nil, model.CacheValidators{}, fmt.Errorf("failed to list collaborator repositories: %w", err)
+	}
+	return repos, latest, err
+}
+
+// ListPullRequests lists the open pull requests of a repository, most recently
+// updated first
+func (c *RealClient) ListPullRequests(org, repo, token string) ([]model.GitHubPullRequest, error) {
+	client, err := c.createGitHubClient(token)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+
+	var allPullRequests []model.GitHubPullRequest
+	opts := &github.PullRequestListOptions{
+		State:       "open",
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		pullRequests, resp, err := client.PullRequests.List(ctx, org, repo, opts)
+		if err := c.observe(token, resp, err); err != nil {
+			return nil, fmt.Errorf("failed to list pull requests for %s/%s: %w", org, repo, err)
 		}
-	} else {
-		// Use public user endpoint (only public repos)
-		opts := &github.RepositoryListByUserOptions{
-			Type: "all",
-			Sort: "updated",
-			Direction: "desc",
-			ListOptions: github.ListOptions{
-				PerPage: 100,
-			},
+
+		for _, pr := range pullRequests {
+			allPullRequests = append(allPullRequests, model.GitHubPullRequest{
+				Repo:    org + "/" + repo,
+				Number:  pr.GetNumber(),
+				Title:   pr.GetTitle(),
+				Author:  pr.GetUser().GetLogin(),
+				HeadRef: pr.GetHead().GetRef(),
+				HTMLURL: pr.GetHTMLURL(),
+				Draft:   pr.GetDraft(),
+			})
 		}
 
-		for {
-			repos, resp, err := client.Repositories.ListByUser(ctx, username, opts)
-			if err != nil {
-				return nil, fmt.Errorf("failed to list repositories for user %s: %w", username, err)
-			}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
 
-			for _, repo := range repos {
-				allRepos = append(allRepos, convertRepo(repo))
+	return allPullRequests, nil
+}
+
+// listPages fetches every page of a repository listing. Only the first page is
+// requested conditionally, a change on a later page (like a deleted repository)
+// leaves the first page as it was, so the validators are only kept for
+// listings that fit on a single page.
+func (c *RealClient) listPages(client *github.Client, token, path string, query url.Values, validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error) {
+	ctx := context.Background()
+	var allRepos []model.GitHubRepo
+	var latest model.CacheValidators
+
+	query.Set("per_page", "100")
+	for page := 1; page != 0; {
+		query.Set("page", strconv.Itoa(page))
+		req, err := client.NewRequest(http.MethodGet, path+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, model.CacheValidators{}, err
+		}
+		if page == 1 {
+			if validators.ETag != "" {
+				req.Header.Set("If-None-Match", validators.ETag)
 			}
+			if validators.LastModified != "" {
+				req.Header.Set("If-Modified-Since", validators.LastModified)
+			}
+		}
 
-			if resp.NextPage == 0 {
-				break
+		var repos []*github.Repository
+		resp, err := client.Do(ctx, req, &repos)
+		if resp != nil && resp.StatusCode == http.StatusNotModified {
+			c.observe(token, resp, nil)
+			return nil, validators, ErrNotModified
+		}
+		if err := c.observe(token, resp, err); err != nil {
+			return nil, model.CacheValidators{}, err
+		}
+		if page == 1 && resp.NextPage == 0 {
+			latest = model.CacheValidators{
+				ETag:         resp.Header.Get("ETag"),
+				LastModified: resp.Header.Get("Last-Modified"),
 			}
-			opts.Page = resp.NextPage
 		}
+
+		for _, repo := range repos {
+			allRepos = append(allRepos, convertRepo(repo))
+		}
+		page = resp.NextPage
 	}
 
-	return allRepos, nil
+	return allRepos, latest, nil
 }
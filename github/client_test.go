@@ -0,0 +1,253 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/cache"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/stretchr/testify/assert"
+)
+
+const repoJSON = `{"id": %d, "name": %q, "full_name": "acme/%s", "clone_url": "https://github.example.com/acme/%s.git",
+"ssh_url": "git@github.example.com:acme/%s.git", "html_url": "https://github.example.com/acme/%s",
+"private": true, "fork": false, "archived": false, "disabled": false,
+"updated_at": "2024-01-01T00:00:00Z", "pushed_at": "2024-01-01T00:00:00Z"}`
+
+func repoPage(id int, name string) string {
+	return fmt.Sprintf(repoJSON, id, name, name, name, name, name)
+}
+
+func newTestClient(t *testing.T) Client {
+	t.Setenv("HOME", t.TempDir())
+	return NewClient("", NewRateLimits(cache.NewCache(home.NewHome(oswrap.NewOs()))))
+}
+
+func TestEnterpriseClient(t *testing.T) {
+	var authorization string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprintf(w, "[%s]", repoPage(2, "web"))
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s/api/v3/orgs/acme/repos?page=2>; rel="next"`, r.Host))
+		fmt.Fprintf(w, "[%s]", repoPage(1, "api"))
+	})
+	mux.HandleFunc("/api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "wile"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := newTestClient(t).WithBaseURL(server.URL)
+
+	t.Run("should list repos from the enterprise api", func(t *testing.T) {
+		repos, err := client.ListOrgReposWithToken("acme", "enterprise-token")
+		assert.Nil(t, err)
+		assert.Len(t, repos, 2)
+		assert.Equal(t, "api", repos[0].Name)
+		assert.Equal(t, "git@github.example.com:acme/web.git", repos[1].SSHURL)
+		assert.Equal(t, "Bearer enterprise-token", authorization)
+	})
+
+	t.Run("should get the authenticated user from the enterprise api", func(t *testing.T) {
+		username, err := client.GetAuthenticatedUsername("enterprise-token")
+		assert.Nil(t, err)
+		assert.Equal(t, "wile", username)
+	})
+
+	t.Run("should not send github.com tokens to the enterprise api", func(t *testing.T) {
+		t.Setenv("GITHUB_TOKEN", "github-token")
+		t.Setenv("HOME", t.TempDir())
+		github := NewClient("default-token", NewRateLimits(cache.NewCache(home.NewHome(oswrap.NewOs()))))
+		_, err := github.WithBaseURL(server.URL).ListOrgReposWithToken("acme", "")
+		assert.Nil(t, err)
+		assert.Equal(t, "", authorization)
+	})
+
+	t.Run("should report an invalid base url", func(t *testing.T) {
+		_, err := newTestClient(t).WithBaseURL("://nope").ListOrgReposWithToken("acme", "token")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestConditionalRequests(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		fmt.Fprintf(w, "[%s]", repoPage(1, "api"))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t).WithBaseURL(server.URL + "/api/v3/")
+
+	t.Run("should return the validators of the first page", func(t *testing.T) {
+		repos, validators, err := client.ListOrgReposConditional("acme", "token", model.CacheValidators{})
+		assert.Nil(t, err)
+		assert.Len(t, repos, 1)
+		assert.Equal(t, model.CacheValidators{ETag: `"v1"`, LastModified: "Mon, 01 Jan 2024 00:00:00 GMT"}, validators)
+	})
+
+	t.Run("should report unchanged repos", func(t *testing.T) {
+		validators := model.CacheValidators{ETag: `"v1"`}
+		repos, latest, err := client.ListOrgReposConditional("acme", "token", validators)
+		assert.ErrorIs(t, err, ErrNotModified)
+		assert.Nil(t, repos)
+		assert.Equal(t, validators, latest)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("should not return validators for listings with several pages", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"v1"`)
+			if r.URL.Query().Get("page") == "2" {
+				fmt.Fprintf(w, "[%s]", repoPage(2, "web"))
+				return
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/api/v3/orgs/acme/repos?page=2>; rel="next"`, r.Host))
+			fmt.Fprintf(w, "[%s]", repoPage(1, "api"))
+		}))
+		defer server.Close()
+
+		repos, validators, err := newTestClient(t).WithBaseURL(server.URL+"/api/v3/").ListOrgReposConditional("acme", "token", model.CacheValidators{})
+		assert.Nil(t, err)
+		assert.Len(t, repos, 2)
+		assert.Equal(t, model.CacheValidators{}, validators)
+	})
+}
+
+func TestSourceEndpoints(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+"?"+r.URL.Query().Get("affiliation"))
+		fmt.Fprintf(w, "[%s]", repoPage(1, "api"))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t).WithBaseURL(server.URL + "/api/v3/")
+	_, _, err := client.ListStarredReposConditional("token", model.CacheValidators{})
+	assert.Nil(t, err)
+	_, _, err = client.ListTeamReposConditional("acme", "platform", "token", model.CacheValidators{})
+	assert.Nil(t, err)
+	_, _, err = client.ListCollaboratorReposConditional("token", model.CacheValidators{})
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{
+		"/api/v3/user/starred?",
+		"/api/v3/orgs/acme/teams/platform/repos?",
+		"/api/v3/user/repos?collaborator",
+	}, paths)
+}
+
+func TestRateLimits(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		switch r.URL.Path {
+		case "/api/v3/orgs/acme/repos":
+			w.Header().Set("X-RateLimit-Remaining", "1")
+			fmt.Fprintf(w, "[%s]", repoPage(1, "api"))
+		case "/api/v3/orgs/exhausted/repos":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "API rate limit exceeded"}`)
+		case "/api/v3/orgs/burst/repos":
+			w.Header().Set("X-RateLimit-Remaining", "100")
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "You have exceeded a secondary rate limit", "documentation_url": "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`)
+		default:
+			w.Header().Set("X-RateLimit-Remaining", "100")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		}
+	}))
+	defer server.Close()
+
+	setup := func(t *testing.T) (Client, RateLimits) {
+		t.Setenv("HOME", t.TempDir())
+		requests = 0
+		rateLimits := NewRateLimits(cache.NewCache(home.NewHome(oswrap.NewOs())))
+		return NewClient("", rateLimits).WithBaseURL(server.URL + "/api/v3/"), rateLimits
+	}
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	t.Run("should record the remaining quota", func(t *testing.T) {
+		client, rateLimits := setup(t)
+		_, err := client.ListOrgReposWithToken("acme", "token")
+		assert.Nil(t, err)
+		limit, found := rateLimits.Get(host, "token")
+		assert.True(t, found)
+		assert.Equal(t, 5000, limit.Limit)
+		assert.Equal(t, 1, limit.Remaining)
+		assert.True(t, reset.Equal(limit.Reset))
+		assert.Equal(t, TokenID("token"), limit.Token)
+	})
+
+	t.Run("should save the limits once when flushed", func(t *testing.T) {
+		client, rateLimits := setup(t)
+		_, err := client.ListOrgReposWithToken("acme", "token")
+		assert.Nil(t, err)
+		_, err = client.ListOrgReposWithToken("acme", "token")
+		assert.Nil(t, err)
+		saved := NewRateLimits(cache.NewCache(home.NewHome(oswrap.NewOs())))
+		assert.Empty(t, saved.All())
+
+		assert.Nil(t, rateLimits.Flush())
+		saved = NewRateLimits(cache.NewCache(home.NewHome(oswrap.NewOs())))
+		limit, found := saved.Get(host, "token")
+		assert.True(t, found)
+		assert.Equal(t, 1, limit.Remaining)
+	})
+
+	t.Run("should skip requests until the rate limit resets", func(t *testing.T) {
+		client, _ := setup(t)
+		_, err := client.ListOrgReposWithToken("exhausted", "token")
+		assert.ErrorIs(t, err, ErrRateLimited)
+		_, err = client.ListOrgReposWithToken("acme", "token")
+		assert.ErrorIs(t, err, ErrRateLimited)
+		assert.Equal(t, 1, requests)
+
+		_, err = client.ListOrgReposWithToken("acme", "other-token")
+		assert.Nil(t, err, "other tokens have their own quota")
+	})
+
+	t.Run("should back off after a secondary rate limit", func(t *testing.T) {
+		client, rateLimits := setup(t)
+		_, err := client.ListOrgReposWithToken("burst", "token")
+		var rateLimitErr *RateLimitError
+		assert.ErrorAs(t, err, &rateLimitErr)
+		assert.True(t, rateLimitErr.Secondary)
+		limit, _ := rateLimits.Get(host, "token")
+		assert.WithinDuration(t, time.Now().Add(time.Minute), limit.RetryAfter, 5*time.Second)
+
+		_, err = client.ListOrgReposWithToken("acme", "token")
+		assert.ErrorIs(t, err, ErrRateLimited)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("should return a typed error for missing orgs", func(t *testing.T) {
+		client, _ := setup(t)
+		_, _, err := client.ListOrgReposConditional("missing", "token", model.CacheValidators{})
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NotErrorIs(t, err, ErrRateLimited)
+	})
+}
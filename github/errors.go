@@ -0,0 +1,58 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/joshmedeski/sesh/v2/model"
+)
+
+var (
+	// ErrNotFound is returned when an organization, user or repository
+	// doesn't exist or isn't visible to the token
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited is returned when the rate limit of a token is exhausted
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// RateLimitError is returned instead of requests that GitHub would reject
+// until the rate limit resets
+type RateLimitError struct {
+	RateLimit model.GitHubRateLimit
+	// Until is when requests are accepted again
+	Until time.Time
+	// Secondary is set for secondary rate limits
+	Secondary bool
+}
+
+func (e *RateLimitError) Error() string {
+	kind := "rate limit"
+	if e.Secondary {
+		kind = "secondary rate limit"
+	}
+	return fmt.Sprintf("GitHub %s exceeded on %s, retry after %s", kind, e.RateLimit.Host, e.Until.Local().Format(time.TimeOnly))
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// convertError maps the errors of go-github to the errors of this package
+func convertError(err error, limit model.GitHubRateLimit) error {
+	var rateLimitErr *github.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return &RateLimitError{RateLimit: limit, Until: rateLimitErr.Rate.Reset.Time}
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		return &RateLimitError{RateLimit: limit, Until: limit.RetryAfter, Secondary: true}
+	}
+	var responseErr *github.ErrorResponse
+	if errors.As(err, &responseErr) && responseErr.Response != nil && responseErr.Response.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
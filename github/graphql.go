@@ -0,0 +1,222 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/joshmedeski/sesh/v2/model"
+)
+
+// graphQLBatchSize is how many owners are listed per query, every owner adds
+// up to 100 repositories with 20 topics each to the node limit of a query
+const graphQLBatchSize = 10
+
+// graphQLRepoFields are the fields of model.GitHubRepo
+const graphQLRepoFields = `databaseId name nameWithOwner description url sshUrl isPrivate isFork isArchived isDisabled
+primaryLanguage { name } updatedAt pushedAt repositoryTopics(first: 20) { nodes { topic { name } } }`
+
+type graphQLRepo struct {
+	DatabaseID      int    `json:"databaseId"`
+	Name            string `json:"name"`
+	NameWithOwner   string `json:"nameWithOwner"`
+	Description     string `json:"description"`
+	URL             string `json:"url"`
+	SSHURL          string `json:"sshUrl"`
+	IsPrivate       bool   `json:"isPrivate"`
+	IsFork          bool   `json:"isFork"`
+	IsArchived      bool   `json:"isArchived"`
+	IsDisabled      bool   `json:"isDisabled"`
+	PrimaryLanguage *struct {
+		Name string `json:"name"`
+	} `json:"primaryLanguage"`
+	UpdatedAt        string `json:"updatedAt"`
+	PushedAt         string `json:"pushedAt"`
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name string `json:"name"`
+			} `json:"topic"`
+		} `json:"nodes"`
+	} `json:"repositoryTopics"`
+}
+
+type graphQLOwner struct {
+	Repositories struct {
+		PageInfo struct {
+			HasNextPage bool   `json:"hasNextPage"`
+			EndCursor   string `json:"endCursor"`
+		} `json:"pageInfo"`
+		Nodes []graphQLRepo `json:"nodes"`
+	} `json:"repositories"`
+}
+
+type graphQLResponse struct {
+	Data   map[string]*graphQLOwner `json:"data"`
+	Errors []struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// graphQLOwnerQuery is the next page of an owner, an empty login is the viewer
+type graphQLOwnerQuery struct {
+	key    string
+	login  string
+	cursor string
+}
+
+func (q *graphQLOwnerQuery) alias(i int) string {
+	if q.login == "" {
+		return "viewer"
+	}
+	return fmt.Sprintf("o%d", i)
+}
+
+// ListReposGraphQL lists the repositories of organizations or users, and
+// those the viewer owns, with batched GraphQL queries. The viewer's
+// repositories are keyed by model.PersonalCacheKey, owners that don't exist
+// are left out.
+func (c *RealClient) ListReposGraphQL(owners []string, viewer bool, token string) (map[string][]model.GitHubRepo, error) {
+	client, err := c.createGitHubClient(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*graphQLOwnerQuery
+	if viewer {
+		pending = append(pending, &graphQLOwnerQuery{key: model.PersonalCacheKey})
+	}
+	for _, owner := range owners {
+		pending = append(pending, &graphQLOwnerQuery{key: owner, login: owner})
+	}
+
+	results := make(map[string][]model.GitHubRepo)
+	for len(pending) > 0 {
+		batch := pending[:min(len(pending), graphQLBatchSize)]
+		data, err := c.queryRepos(client, token, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		// Owners with more repositories are queried again with the next batch
+		var next []*graphQLOwnerQuery
+		for i, owner := range batch {
+			result := data[owner.alias(i)]
+			if result == nil {
+				slog.Debug("GitHub owner not found", "owner", owner.login)
+				continue
+			}
+			repos := results[owner.key]
+			for _, repo := range result.Repositories.Nodes {
+				repos = append(repos, repo.toModel())
+			}
+			results[owner.key] = repos
+			if result.Repositories.PageInfo.HasNextPage {
+				owner.cursor = result.Repositories.PageInfo.EndCursor
+				next = append(next, owner)
+			}
+		}
+		pending = append(next, pending[len(batch):]...)
+	}
+
+	return results, nil
+}
+
+func (c *RealClient) queryRepos(client *github.Client, token string, batch []*graphQLOwnerQuery) (map[string]*graphQLOwner, error) {
+	var params, fields []string
+	variables := make(map[string]any)
+	for i, owner := range batch {
+		cursor := fmt.Sprintf("c%d", i)
+		params = append(params, "$"+cursor+": String")
+		variables[cursor] = nil
+		if owner.cursor != "" {
+			variables[cursor] = owner.cursor
+		}
+
+		alias := owner.alias(i)
+		if owner.login == "" {
+			fields = append(fields, fmt.Sprintf("viewer { %s }", graphQLRepositories(cursor, "ownerAffiliations: [OWNER], ")))
+			continue
+		}
+		params = append(params, "$"+alias+": String!")
+		variables[alias] = owner.login
+		fields = append(fields, fmt.Sprintf("%s: repositoryOwner(login: $%s) { %s }", alias, alias, graphQLRepositories(cursor, "")))
+	}
+	query := fmt.Sprintf("query(%s) { %s }", strings.Join(params, ", "), strings.Join(fields, " "))
+
+	req, err := client.NewRequest(http.MethodPost, c.graphQLPath(), map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return nil, err
+	}
+	var response graphQLResponse
+	resp, err := client.Do(context.Background(), req, &response)
+	if err := c.observe(token, resp, err); err != nil {
+		return nil, fmt.Errorf("failed to query repositories: %w", err)
+	}
+
+	var messages []string
+	for _, queryErr := range response.Errors {
+		switch queryErr.Type {
+		case "RATE_LIMITED":
+			limit, _ := c.rateLimits.Get(c.host(), c.resolveToken(token))
+			return nil, &RateLimitError{RateLimit: limit, Until: limit.Reset}
+		case "NOT_FOUND":
+			// Missing owners are null in the data
+		default:
+			messages = append(messages, queryErr.Message)
+		}
+	}
+	if len(messages) > 0 && response.Data == nil {
+		return nil, fmt.Errorf("failed to query repositories: %s", strings.Join(messages, ", "))
+	}
+	for _, message := range messages {
+		slog.Warn("GitHub GraphQL query returned an error", "error", message)
+	}
+	return response.Data, nil
+}
+
+// graphQLPath is relative to the REST API, which is /api/v3/ on GitHub
+// Enterprise Server and /api/graphql is next to it
+func (c *RealClient) graphQLPath() string {
+	if c.baseURL == "" {
+		return "graphql"
+	}
+	return "../graphql"
+}
+
+func graphQLRepositories(cursor, args string) string {
+	return fmt.Sprintf("repositories(first: 100, after: $%s, %sorderBy: {field: UPDATED_AT, direction: DESC}) { pageInfo { hasNextPage endCursor } nodes { %s } }", cursor, args, graphQLRepoFields)
+}
+
+func (r graphQLRepo) toModel() model.GitHubRepo {
+	var language string
+	if r.PrimaryLanguage != nil {
+		language = r.PrimaryLanguage.Name
+	}
+	var topics []string
+	for _, node := range r.RepositoryTopics.Nodes {
+		topics = append(topics, node.Topic.Name)
+	}
+
+	return model.GitHubRepo{
+		ID:          r.DatabaseID,
+		Name:        r.Name,
+		FullName:    r.NameWithOwner,
+		Description: r.Description,
+		CloneURL:    r.URL + ".git",
+		SSHURL:      r.SSHURL,
+		HTMLURL:     r.URL,
+		Private:     r.IsPrivate,
+		Fork:        r.IsFork,
+		Archived:    r.IsArchived,
+		Disabled:    r.IsDisabled,
+		Language:    language,
+		UpdatedAt:   r.UpdatedAt,
+		PushedAt:    r.PushedAt,
+		Topics:      topics,
+	}
+}
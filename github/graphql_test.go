@@ -0,0 +1,147 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/cache"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGitHub serves the repos of its owners from the REST and GraphQL APIs,
+// every request takes latency like a round trip to GitHub would
+type fakeGitHub struct {
+	repos   map[string]int
+	latency time.Duration
+	queries []string
+}
+
+func (f *fakeGitHub) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/orgs/{owner}/repos", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(f.latency)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		owner := r.PathValue("owner")
+		start, end := f.page(owner, max(page, 1)-1)
+		if end < f.repos[owner] {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=%d>; rel="next"`, r.Host, r.URL.Path, max(page, 1)+1))
+		}
+		var repos []string
+		for id := start; id < end; id++ {
+			name := fmt.Sprintf("repo-%d", id)
+			repos = append(repos, fmt.Sprintf(repoJSON, id, name, name, name, name, name))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(repos, ","))
+	})
+	mux.HandleFunc("/api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(f.latency)
+		var request struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		f.queries = append(f.queries, request.Query)
+
+		data := make(map[string]any)
+		for alias, owner := range request.Variables {
+			if !strings.HasPrefix(alias, "o") {
+				continue
+			}
+			if _, ok := f.repos[owner]; !ok {
+				data[alias] = nil
+				continue
+			}
+			cursor, _ := strconv.Atoi(request.Variables["c"+alias[1:]])
+			start, end := f.page(owner, cursor)
+			var nodes []map[string]any
+			for id := start; id < end; id++ {
+				name := fmt.Sprintf("repo-%d", id)
+				nodes = append(nodes, map[string]any{
+					"databaseId": id, "name": name, "nameWithOwner": owner + "/" + name,
+					"url": "https://github.example.com/" + owner + "/" + name, "sshUrl": "git@github.example.com:" + owner + "/" + name + ".git",
+					"isPrivate": true, "primaryLanguage": map[string]string{"name": "Go"},
+					"updatedAt": "2024-01-01T00:00:00Z", "pushedAt": "2024-01-01T00:00:00Z",
+					"repositoryTopics": map[string]any{"nodes": []any{map[string]any{"topic": map[string]string{"name": "cli"}}}},
+				})
+			}
+			data[alias] = map[string]any{"repositories": map[string]any{
+				"pageInfo": map[string]any{"hasNextPage": end < f.repos[owner], "endCursor": strconv.Itoa(cursor + 1)},
+				"nodes":    nodes,
+			}}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	})
+	return mux
+}
+
+// page is the range of repo ids on a page of 100
+func (f *fakeGitHub) page(owner string, page int) (int, int) {
+	start := page * 100
+	return start, min(start+100, f.repos[owner])
+}
+
+func newFakeGitHubClient(t testing.TB, fake *fakeGitHub) Client {
+	server := httptest.NewServer(fake.handler())
+	t.Cleanup(server.Close)
+	t.Setenv("HOME", t.TempDir())
+	rateLimits := NewRateLimits(cache.NewCache(home.NewHome(oswrap.NewOs())))
+	return NewClient("", rateLimits).WithBaseURL(server.URL + "/api/v3/")
+}
+
+func TestListReposGraphQL(t *testing.T) {
+	fake := &fakeGitHub{repos: map[string]int{"acme": 250, "wile": 3}}
+	client := newFakeGitHubClient(t, fake)
+
+	results, err := client.ListReposGraphQL([]string{"acme", "wile", "missing"}, false, "token")
+	assert.Nil(t, err)
+	assert.Len(t, results["acme"], 250)
+	assert.Len(t, results["wile"], 3)
+	assert.NotContains(t, results, "missing")
+	assert.Len(t, fake.queries, 3, "acme has three pages, the other owners are in the first batch")
+
+	repo := results["wile"][0]
+	assert.Equal(t, "wile/repo-0", repo.FullName)
+	assert.Equal(t, "https://github.example.com/wile/repo-0.git", repo.CloneURL)
+	assert.Equal(t, "Go", repo.Language)
+	assert.Equal(t, []string{"cli"}, repo.Topics)
+	assert.True(t, repo.Private)
+}
+
+// BenchmarkListRepos compares listing 20 organizations of 150 repos through
+// REST, one organization and page at a time, with batched GraphQL queries
+func BenchmarkListRepos(b *testing.B) {
+	fake := &fakeGitHub{repos: make(map[string]int), latency: 5 * time.Millisecond}
+	var owners []string
+	for i := range 20 {
+		owner := fmt.Sprintf("org-%d", i)
+		fake.repos[owner] = 150
+		owners = append(owners, owner)
+	}
+	client := newFakeGitHubClient(b, fake)
+
+	b.Run("REST", func(b *testing.B) {
+		for range b.N {
+			for _, owner := range owners {
+				if _, err := client.ListOrgReposWithToken(owner, "token"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("GraphQL", func(b *testing.B) {
+		for range b.N {
+			if _, err := client.ListReposGraphQL(owners, false, "token"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
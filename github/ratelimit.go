@@ -0,0 +1,124 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/cache"
+	"github.com/joshmedeski/sesh/v2/model"
+)
+
+// RateLimitSource is the source of GitHub rate limits in the sesh cache
+const RateLimitSource = "github-ratelimit"
+
+// rateLimitTTL is how long a rate limit is shown after the last response
+const rateLimitTTL = 24 * time.Hour
+
+// RateLimits remembers the quota of each token across sesh runs, so requests
+// that GitHub would reject aren't sent at all. The limits are read once and
+// kept in memory, Flush saves the ones that changed during the run.
+type RateLimits interface {
+	Get(host, token string) (model.GitHubRateLimit, bool)
+	Set(limit model.GitHubRateLimit)
+	All() []model.GitHubRateLimit
+	Flush() error
+}
+
+type RealRateLimits struct {
+	store  cache.Cache
+	mu     sync.Mutex
+	limits map[string]model.GitHubRateLimit
+	// changed are the scopes set during this run
+	changed map[string]bool
+}
+
+func NewRateLimits(store cache.Cache) RateLimits {
+	return &RealRateLimits{store: store, changed: make(map[string]bool)}
+}
+
+// TokenID identifies a token without revealing it
+func TokenID(token string) string {
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:4])
+}
+
+func rateLimitScope(host, tokenID string) string {
+	if tokenID == "" {
+		return host
+	}
+	return host + "/" + tokenID
+}
+
+// load reads the saved limits the first time they're needed, the caller
+// holds the lock
+func (r *RealRateLimits) load() {
+	if r.limits != nil {
+		return
+	}
+	r.limits = make(map[string]model.GitHubRateLimit)
+	entries, err := r.store.Entries(RateLimitSource)
+	if err != nil {
+		slog.Warn("Failed to read GitHub rate limits", "error", err)
+		return
+	}
+	for _, entry := range entries {
+		if entry.Expired() {
+			continue
+		}
+		var limit model.GitHubRateLimit
+		if _, found := r.store.Get(RateLimitSource, entry.Scope, &limit); found {
+			r.limits[entry.Scope] = limit
+		}
+	}
+}
+
+func (r *RealRateLimits) Get(host, token string) (model.GitHubRateLimit, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.load()
+	limit, found := r.limits[rateLimitScope(host, TokenID(token))]
+	return limit, found
+}
+
+func (r *RealRateLimits) Set(limit model.GitHubRateLimit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.load()
+	scope := rateLimitScope(limit.Host, limit.Token)
+	r.limits[scope] = limit
+	r.changed[scope] = true
+}
+
+func (r *RealRateLimits) All() []model.GitHubRateLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.load()
+	var limits []model.GitHubRateLimit
+	for _, scope := range slices.Sorted(maps.Keys(r.limits)) {
+		limits = append(limits, r.limits[scope])
+	}
+	return limits
+}
+
+func (r *RealRateLimits) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var errs []error
+	for scope := range r.changed {
+		if err := r.store.Set(RateLimitSource, scope, r.limits[scope], rateLimitTTL); err != nil {
+			errs = append(errs, fmt.Errorf("failed to save GitHub rate limit of %s: %w", scope, err))
+		}
+	}
+	r.changed = make(map[string]bool)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,60 @@
+package github
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// refreshInterval is how long to wait before starting another background refresh
+const refreshInterval = time.Minute
+
+// Refresher updates the cache without blocking the current command
+type Refresher interface {
+	// Refresh starts `sesh cache refresh` as a detached process
+	Refresh() error
+}
+
+type RealRefresher struct {
+	cache      Cache
+	configPath string // passed on so the refresh reads the same config
+}
+
+func NewRefresher(cache Cache, configPath string) Refresher {
+	return &RealRefresher{cache: cache, configPath: configPath}
+}
+
+func (r *RealRefresher) Refresh() error {
+	// Every stale listing would start a refresh, the marker limits it to one
+	// per interval
+	marker := filepath.Join(r.cache.GetCachePath(), ".refreshing")
+	if info, err := os.Stat(marker); err == nil && time.Since(info.ModTime()) < refreshInterval {
+		slog.Debug("Background refresh already started", "at", info.ModTime())
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(marker), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		return fmt.Errorf("failed to write refresh marker: %w", err)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the sesh executable: %w", err)
+	}
+	args := []string{"cache", "refresh"}
+	if r.configPath != "" {
+		args = append(args, "--config", r.configPath)
+	}
+	cmd := exec.Command(executable, args...)
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start background refresh: %w", err)
+	}
+	slog.Debug("Started background refresh", "pid", cmd.Process.Pid)
+	return cmd.Process.Release()
+}
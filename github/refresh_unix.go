@@ -0,0 +1,11 @@
+//go:build unix
+
+package github
+
+import "syscall"
+
+// detachedProcAttr starts the refresh in its own session so it outlives the
+// terminal and the tmux popup sesh was run from
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
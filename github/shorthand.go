@@ -2,9 +2,8 @@ package github
 
 import (
 	"fmt"
-	"os"
 	"regexp"
-	"strings"
+	"strconv"
 
 	"github.com/joshmedeski/sesh/v2/model"
 )
@@ -13,11 +12,16 @@ import (
 var githubShorthandRegex = regexp.MustCompile(`^([a-zA-Z0-9._-]+)\/([a-zA-Z0-9._-]+)$`)
 var githubURLRegex = regexp.MustCompile(`^(?:https?://)?(?:www\.)?github\.com/([a-zA-Z0-9._-]+)/([a-zA-Z0-9._-]+)(?:\.git)?/?$`)
 
+// Pull request patterns: org/repo#123 and https://<host>/org/repo/pull/123
+var pullRequestShorthandRegex = regexp.MustCompile(`^([a-zA-Z0-9._-]+)/([a-zA-Z0-9._-]+)#([0-9]+)$`)
+var pullRequestURLRegex = regexp.MustCompile(`^(?:https?://)?[a-zA-Z0-9.-]+(?::[0-9]+)?/([a-zA-Z0-9._-]+)/([a-zA-Z0-9._-]+)/pull/([0-9]+)(?:/.*)?$`)
+
 type ShorthandConverter interface {
 	IsGitHubShorthand(input string) bool
 	ConvertToURL(input string, config model.GitHubConfig) (string, error)
 	ExtractOrgAndRepo(input string) (org, repo string, err error)
-	GetClonePath(org, repo string, config model.GitHubConfig) string
+	GetClonePath(org, repo string, config model.Config) (string, error)
+	ExtractPullRequest(input string) (org, repo string, number int, err error)
 }
 
 type RealShorthandConverter struct{}
@@ -48,11 +52,12 @@ func (c *RealShorthandConverter) ConvertToURL(input string, config model.GitHubC
 		return "", err
 	}
 	
+	host := hostForOrg(org, config)
 	if config.UseSSH {
-		return fmt.Sprintf("git@github.com:%s/%s.git", org, repo), nil
+		return fmt.Sprintf("git@%s:%s/%s.git", host, org, repo), nil
 	}
 	
-	return fmt.Sprintf("https://github.com/%s/%s.git", org, repo), nil
+	return fmt.Sprintf("https://%s/%s/%s.git", host, org, repo), nil
 }
 
 // ExtractOrgAndRepo extracts organization and repository names from various GitHub input formats
@@ -70,22 +75,33 @@ func (c *RealShorthandConverter) ExtractOrgAndRepo(input string) (string, string
 	return "", "", fmt.Errorf("invalid GitHub shorthand format: %s", input)
 }
 
-// GetClonePath determines where to clone the repository
-func (c *RealShorthandConverter) GetClonePath(org, repo string, config model.GitHubConfig) string {
-	if config.CloneDir != "" {
-		return expandHome(fmt.Sprintf("%s/github.com/%s/%s", config.CloneDir, org, repo))
-	}
-	
-	// Default to ~/git/github.com/org/repo
-	return expandHome(fmt.Sprintf("~/git/github.com/%s/%s", org, repo))
+// GetClonePath determines where to clone the repository with the clone path
+// template of its host, the path may start with ~
+func (c *RealShorthandConverter) GetClonePath(org, repo string, config model.Config) (string, error) {
+	remote := model.GitRemote{Host: hostForOrg(org, config.GitHub), Path: org + "/" + repo}
+	return config.Clone.ClonePath(remote, config.GitHub.CloneDir)
 }
 
-// expandHome expands ~ to user home directory
-func expandHome(path string) string {
-	if strings.HasPrefix(path, "~/") {
-		if homeDir, err := os.UserHomeDir(); err == nil {
-			return strings.Replace(path, "~", homeDir, 1)
+// ExtractPullRequest extracts the organization, repository and number from
+// org/repo#123 or a pull request url
+func (c *RealShorthandConverter) ExtractPullRequest(input string) (string, string, int, error) {
+	for _, pattern := range []*regexp.Regexp{pullRequestShorthandRegex, pullRequestURLRegex} {
+		if matches := pattern.FindStringSubmatch(input); len(matches) == 4 {
+			number, err := strconv.Atoi(matches[3])
+			if err != nil {
+				return "", "", 0, fmt.Errorf("invalid pull request number %s: %w", matches[3], err)
+			}
+			return matches[1], matches[2], number, nil
 		}
 	}
-	return path
+	return "", "", 0, fmt.Errorf("invalid pull request: %s, expected org/repo#123 or a pull request url", input)
 }
+
+// hostForOrg returns the host of a configured organization, or github.com
+func hostForOrg(org string, config model.GitHubConfig) string {
+	if orgConfig, ok := config.GetOrgConfig(org); ok {
+		return orgConfig.GetHost()
+	}
+	return model.DefaultGitHubHost
+}
+
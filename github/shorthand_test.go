@@ -0,0 +1,58 @@
+package github
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShorthandConverterEnterprise(t *testing.T) {
+	converter := NewShorthandConverter()
+	config := model.GitHubConfig{
+		CloneDir: "/src",
+		Organizations: []model.GitHubOrgConfig{
+			{Name: "acme", Host: "github.example.com"},
+			{Name: "widgets", BaseURL: "https://ghe.widgets.io/api/v3/"},
+		},
+	}
+
+	t.Run("should use the host of the organization", func(t *testing.T) {
+		url, err := converter.ConvertToURL("acme/api", config)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://github.example.com/acme/api.git", url)
+		path, err := converter.GetClonePath("acme", "api", model.Config{GitHub: config})
+		assert.Nil(t, err)
+		assert.Equal(t, "/src/github.example.com/acme/api", path)
+	})
+
+	t.Run("should derive the host from the base url", func(t *testing.T) {
+		config := config
+		config.UseSSH = true
+		url, err := converter.ConvertToURL("widgets/app", config)
+		assert.Nil(t, err)
+		assert.Equal(t, "git@ghe.widgets.io:widgets/app.git", url)
+	})
+
+	t.Run("should default to github.com", func(t *testing.T) {
+		url, err := converter.ConvertToURL("joshmedeski/sesh", model.GitHubConfig{})
+		assert.Nil(t, err)
+		assert.Equal(t, "https://github.com/joshmedeski/sesh.git", url)
+		path, err := converter.GetClonePath("joshmedeski", "sesh", model.Config{})
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.FromSlash("~/git/github.com/joshmedeski/sesh"), path)
+	})
+}
+
+func TestExtractPullRequest(t *testing.T) {
+	converter := NewShorthandConverter()
+	for _, input := range []string{"acme/api#42", "https://github.com/acme/api/pull/42", "github.example.com/acme/api/pull/42/files"} {
+		org, repo, number, err := converter.ExtractPullRequest(input)
+		assert.Nil(t, err, input)
+		assert.Equal(t, []any{"acme", "api", 42}, []any{org, repo, number}, input)
+	}
+
+	_, _, _, err := converter.ExtractPullRequest("acme/api")
+	assert.NotNil(t, err)
+}
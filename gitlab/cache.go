@@ -0,0 +1,56 @@
+package gitlab
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/cache"
+	"github.com/joshmedeski/sesh/v2/model"
+)
+
+// CacheSource is the source of GitLab listings in the sesh cache
+const CacheSource = "gitlab"
+
+type Cache interface {
+	Get(key string) ([]model.GitLabProject, bool)
+	Set(key string, projects []model.GitLabProject, timeout int)
+	GetCachePath() string
+}
+
+type RealCache struct {
+	store cache.Cache
+}
+
+func NewCache(store cache.Cache) Cache {
+	return &RealCache{
+		store: store,
+	}
+}
+
+func (c *RealCache) Get(key string) ([]model.GitLabProject, bool) {
+	var projects []model.GitLabProject
+	entry, found := c.store.Get(CacheSource, key, &projects)
+	if !found {
+		return nil, false
+	}
+
+	if entry.Expired() {
+		slog.Debug("Cache expired", "expired_at", entry.ExpiresAt)
+		return nil, false
+	}
+
+	slog.Debug("Cache hit", "key", key, "projects_count", len(projects))
+	return projects, true
+}
+
+func (c *RealCache) Set(key string, projects []model.GitLabProject, timeout int) {
+	if err := c.store.Set(CacheSource, key, projects, time.Duration(timeout)*time.Minute); err != nil {
+		slog.Error("Failed to write cache", "key", key, "error", err)
+		return
+	}
+	slog.Debug("Cache updated", "key", key, "projects_count", len(projects))
+}
+
+func (c *RealCache) GetCachePath() string {
+	return c.store.Dir(CacheSource)
+}
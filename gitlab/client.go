@@ -0,0 +1,80 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/configurator"
+	"github.com/joshmedeski/sesh/v2/model"
+)
+
+// Client interface for GitLab operations
+type Client interface {
+	ListGroupProjects(group string, includeSubgroups bool) ([]model.GitLabProject, error)
+}
+
+// RealClient talks to the GitLab REST API (v4)
+type RealClient struct {
+	config model.GitLabConfig
+	tokens configurator.TokenResolver
+	http   *http.Client
+}
+
+// NewClient creates a new GitLab client, the token is resolved on the first request
+func NewClient(config model.GitLabConfig, tokens configurator.TokenResolver) Client {
+	return &RealClient{
+		config: config,
+		tokens: tokens,
+		http:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ListGroupProjects lists the projects of a group, following all pages
+func (c *RealClient) ListGroupProjects(group string, includeSubgroups bool) ([]model.GitLabProject, error) {
+	token := c.tokens.GitLabToken(c.config)
+	endpoint := fmt.Sprintf("%s/api/v4/groups/%s/projects", strings.TrimSuffix(c.config.GetURL(), "/"), url.PathEscape(group))
+
+	var allProjects []model.GitLabProject
+	page := "1"
+	for page != "" {
+		query := url.Values{}
+		query.Set("include_subgroups", fmt.Sprintf("%t", includeSubgroups))
+		query.Set("archived", "false")
+		query.Set("order_by", "last_activity_at")
+		query.Set("per_page", "100")
+		query.Set("page", page)
+
+		req, err := http.NewRequest(http.MethodGet, endpoint+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request for group %s: %w", group, err)
+		}
+		if token != "" {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list projects for group %s: %w", group, err)
+		}
+
+		var projects []model.GitLabProject
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list projects for group %s: %s", group, resp.Status)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&projects)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode projects for group %s: %w", group, err)
+		}
+
+		allProjects = append(allProjects, projects...)
+		page = resp.Header.Get("X-Next-Page")
+	}
+
+	return allProjects, nil
+}
@@ -0,0 +1,82 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/cache"
+	"github.com/joshmedeski/sesh/v2/configurator"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/stretchr/testify/assert"
+)
+
+const projectJSON = `{"id": %d, "name": %q, "path_with_namespace": "acme/platform/%s",
+"http_url_to_repo": "https://gitlab.example.com/acme/platform/%s.git",
+"ssh_url_to_repo": "git@gitlab.example.com:acme/platform/%s.git", "archived": false}`
+
+func projectPage(id int, name string) string {
+	return fmt.Sprintf(projectJSON, id, name, name, name, name)
+}
+
+func TestListGroupProjects(t *testing.T) {
+	var token, subgroups string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/groups/acme%2Fplatform/projects" {
+			http.NotFound(w, r)
+			return
+		}
+		token = r.Header.Get("PRIVATE-TOKEN")
+		subgroups = r.URL.Query().Get("include_subgroups")
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprintf(w, "[%s]", projectPage(2, "web"))
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		fmt.Fprintf(w, "[%s]", projectPage(1, "api"))
+	}))
+	defer server.Close()
+
+	config := model.GitLabConfig{URL: server.URL}
+	tokens := new(configurator.MockTokenResolver)
+	tokens.On("GitLabToken", config).Return("gitlab-token")
+
+	t.Run("should follow every page of a group", func(t *testing.T) {
+		client := NewClient(config, tokens)
+		projects, err := client.ListGroupProjects("acme/platform", true)
+		assert.Nil(t, err)
+		assert.Len(t, projects, 2)
+		assert.Equal(t, "acme/platform/api", projects[0].PathWithNamespace)
+		assert.Equal(t, "git@gitlab.example.com:acme/platform/web.git", projects[1].SSHURLToRepo)
+		assert.Equal(t, "gitlab-token", token)
+		assert.Equal(t, "true", subgroups)
+	})
+
+	t.Run("should report unknown groups", func(t *testing.T) {
+		client := NewClient(config, tokens)
+		_, err := client.ListGroupProjects("nope", false)
+		assert.ErrorContains(t, err, "404")
+	})
+}
+
+func TestCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cache := NewCache(cache.NewCache(home.NewHome(oswrap.NewOs())))
+	projects := []model.GitLabProject{{ID: 1, PathWithNamespace: "acme/platform/api"}}
+
+	t.Run("should return cached projects before they expire", func(t *testing.T) {
+		cache.Set("gitlab.example.com/acme/platform", projects, 30)
+		cached, found := cache.Get("gitlab.example.com/acme/platform")
+		assert.True(t, found)
+		assert.Equal(t, projects, cached)
+	})
+
+	t.Run("should miss expired projects", func(t *testing.T) {
+		cache.Set("gitlab.example.com/acme", projects, 0)
+		_, found := cache.Get("gitlab.example.com/acme")
+		assert.False(t, found)
+	})
+}
@@ -0,0 +1,145 @@
+package hooks
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/pathwrap"
+	"github.com/joshmedeski/sesh/v2/shell"
+)
+
+// MarkerFile lists the post_clone hooks that ran in a repository, it's kept
+// in the git directory
+const MarkerFile = "sesh-post-clone"
+
+// hookScript runs a hook command in the repository
+const hookScript = `cd "$1" && eval "$2"`
+
+type Hooks interface {
+	// PostClone runs the post_clone hooks that match the repository and
+	// haven't run in it yet, quiet captures their output
+	PostClone(repoPath string, quiet bool) error
+}
+
+type RealHooks struct {
+	config model.Config
+	shell  shell.Shell
+	git    git.Git
+	home   home.Home
+}
+
+func NewHooks(config model.Config, shell shell.Shell, git git.Git, home home.Home) Hooks {
+	return &RealHooks{config, shell, git, home}
+}
+
+func (h *RealHooks) PostClone(repoPath string, quiet bool) error {
+	if len(h.config.PostClone) == 0 {
+		return nil
+	}
+
+	var host string
+	if remote, err := h.git.RemoteURL(repoPath); err == nil {
+		if parsed, err := model.ParseGitURL(remote); err == nil {
+			host = parsed.Host
+		}
+	}
+	marker, err := h.markerPath(repoPath)
+	if err != nil {
+		return err
+	}
+	done := readMarker(marker)
+
+	for _, hook := range h.config.PostClone {
+		if slices.Contains(done, hook.ID()) {
+			continue
+		}
+		matched, err := h.match(hook, host, repoPath)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			continue
+		}
+
+		slog.Debug("Running post_clone hook", "hook", hook.ID(), "path", repoPath)
+		for _, command := range hook.Commands {
+			if err := h.run(hook.ID(), repoPath, command, quiet); err != nil {
+				return fmt.Errorf("post_clone hook %s failed on %q: %w", hook.ID(), command, err)
+			}
+		}
+		done = append(done, hook.ID())
+		if err := os.WriteFile(marker, []byte(strings.Join(done, "\n")+"\n"), 0644); err != nil {
+			return fmt.Errorf("couldn't record post_clone hook %s: %w", hook.ID(), err)
+		}
+	}
+	return nil
+}
+
+func (h *RealHooks) run(id string, repoPath string, command string, quiet bool) error {
+	args := []string{"-c", hookScript, "sh", repoPath, command}
+	if quiet {
+		_, err := h.shell.Cmd("sh", args...)
+		return err
+	}
+	return h.shell.Stream(fmt.Sprintf("%s: %s", id, command), "sh", args...)
+}
+
+// match reports whether the host and path patterns of the hook match, a hook
+// without patterns matches every repository
+func (h *RealHooks) match(hook model.PostCloneHook, host string, repoPath string) (bool, error) {
+	if hook.Host != "" {
+		matched, err := path.Match(hook.Host, host)
+		if err != nil {
+			return false, fmt.Errorf("invalid host pattern %q in post_clone hook %s: %w", hook.Host, hook.ID(), err)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	if hook.Path != "" {
+		pattern, err := h.home.ExpandHome(hook.Path)
+		if err != nil {
+			return false, fmt.Errorf("couldn't expand home: %w", err)
+		}
+		matched, err := pathwrap.MatchGlob(pattern, repoPath)
+		if err != nil {
+			return false, fmt.Errorf("invalid path pattern %q in post_clone hook %s: %w", hook.Path, hook.ID(), err)
+		}
+		return matched, nil
+	}
+	return true, nil
+}
+
+// markerPath is in the git directory shared by the worktrees of the repository
+func (h *RealHooks) markerPath(repoPath string) (string, error) {
+	_, gitDir, err := h.git.GitCommonDir(repoPath)
+	if err != nil || gitDir == "" {
+		return "", fmt.Errorf("couldn't find the git directory of %s", repoPath)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(repoPath, gitDir)
+	}
+	return filepath.Join(gitDir, MarkerFile), nil
+}
+
+func readMarker(marker string) []string {
+	data, err := os.ReadFile(marker)
+	if err != nil {
+		return nil
+	}
+	var done []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			done = append(done, line)
+		}
+	}
+	return done
+}
@@ -0,0 +1,64 @@
+package hooks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/shell"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostClone(t *testing.T) {
+	config := model.Config{PostClone: []model.PostCloneHook{
+		{Name: "identity", Host: "git.corp", Commands: []string{"git config user.email me@corp.example"}},
+		{Name: "direnv", Path: "~/work/**", Commands: []string{"direnv allow"}},
+		{Commands: []string{"npm ci"}},
+	}}
+	setup := func(remote string) (*shell.MockShell, Hooks, string) {
+		repoPath := t.TempDir()
+		assert.Nil(t, os.Mkdir(filepath.Join(repoPath, ".git"), 0755))
+		mockShell := new(shell.MockShell)
+		mockGit := new(git.MockGit)
+		mockHome := new(home.MockHome)
+		mockGit.On("RemoteURL", repoPath).Return(remote, nil)
+		mockGit.On("GitCommonDir", repoPath).Return(true, ".git", nil)
+		mockHome.On("ExpandHome", "~/work/**").Return("/home/test/work/**", nil)
+		return mockShell, NewHooks(config, mockShell, mockGit, mockHome), repoPath
+	}
+
+	t.Run("should run matching hooks once", func(t *testing.T) {
+		mockShell, hooks, repoPath := setup("ssh://git@git.corp:7999/team/service.git")
+		mockShell.On("Stream", "identity: git config user.email me@corp.example", "sh", "-c", hookScript, "sh", repoPath, "git config user.email me@corp.example").Return(nil).Once()
+		mockShell.On("Stream", mock.Anything, "sh", "-c", hookScript, "sh", repoPath, "npm ci").Return(nil).Once()
+
+		assert.Nil(t, hooks.PostClone(repoPath, false))
+		assert.Nil(t, hooks.PostClone(repoPath, false))
+		mockShell.AssertExpectations(t)
+		marker, _ := os.ReadFile(filepath.Join(repoPath, ".git", MarkerFile))
+		assert.Equal(t, "identity\n"+config.PostClone[2].ID()+"\n", string(marker))
+	})
+
+	t.Run("should capture the output when quiet", func(t *testing.T) {
+		mockShell, hooks, repoPath := setup("git@github.com:acme/api.git")
+		mockShell.On("Cmd", "sh", "-c", hookScript, "sh", repoPath, "npm ci").Return("", nil)
+		assert.Nil(t, hooks.PostClone(repoPath, true))
+		mockShell.AssertNotCalled(t, "Stream", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should run failed hooks again", func(t *testing.T) {
+		mockShell, hooks, repoPath := setup("git@github.com:acme/api.git")
+		mockShell.On("Stream", mock.Anything, "sh", "-c", hookScript, "sh", repoPath, "npm ci").Return(errors.New("exit status 1")).Once()
+		assert.ErrorContains(t, hooks.PostClone(repoPath, false), `failed on "npm ci": exit status 1`)
+		assert.NoFileExists(t, filepath.Join(repoPath, ".git", MarkerFile))
+
+		mockShell.On("Stream", mock.Anything, "sh", "-c", hookScript, "sh", repoPath, "npm ci").Return(nil).Once()
+		assert.Nil(t, hooks.PostClone(repoPath, false))
+		mockShell.AssertExpectations(t)
+	})
+}
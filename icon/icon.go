@@ -10,6 +10,7 @@ import (
 type Icon interface {
 	AddIcon(session model.SeshSession) string
 	RemoveIcon(name string) string
+	SrcIcon(src string) string
 }
 
 type RealIcon struct {
@@ -31,10 +32,8 @@ func ansiString(code int, s string) string {
 	return fmt.Sprintf("\033[%dm%s\033[39m", code, s)
 }
 
-func (i *RealIcon) AddIcon(s model.SeshSession) string {
-	var icon string
-	var colorCode int
-	switch s.Src {
+func srcIcon(src string) (icon string, colorCode int) {
+	switch src {
 	case "tmux":
 		icon = tmuxIcon
 		colorCode = 34 // blue
@@ -48,15 +47,29 @@ func (i *RealIcon) AddIcon(s model.SeshSession) string {
 		icon = configIcon
 		colorCode = 90 // gray
 	case "github":
-		icon = ""  // GitHub icon
+		icon = ""      // GitHub icon
 		colorCode = 35 // magenta
+	case "gitlab":
+		icon = ""      // GitLab icon
+		colorCode = 31 // red
 	}
+	return icon, colorCode
+}
+
+func (i *RealIcon) AddIcon(s model.SeshSession) string {
+	icon, colorCode := srcIcon(s.Src)
 	if icon != "" {
 		return fmt.Sprintf("%s %s", ansiString(colorCode, icon), s.Name)
 	}
 	return s.Name
 }
 
+// SrcIcon returns the plain icon for a source, without any color codes
+func (i *RealIcon) SrcIcon(src string) string {
+	icon, _ := srcIcon(src)
+	return icon
+}
+
 func (i *RealIcon) RemoveIcon(name string) string {
 	if strings.HasPrefix(name, tmuxIcon) || strings.HasPrefix(name, zoxideIcon) || strings.HasPrefix(name, configIcon) || strings.HasPrefix(name, tmuxinatorIcon) {
 		return name[4:]
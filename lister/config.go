@@ -22,7 +22,7 @@ func listConfig(l *RealLister, opts ListOptions) (model.SeshSessions, error) {
 				return model.SeshSessions{}, fmt.Errorf("couldn't expand home: %q", err)
 			}
 
-			if session.StartupCommand != "" && session.DisableStartCommand {
+			if len(session.StartupCommand) > 0 && session.DisableStartCommand {
 				return model.SeshSessions{}, fmt.Errorf("startup_command and disable_start_command are mutually exclusive")
 			}
 
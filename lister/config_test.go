@@ -27,7 +27,7 @@ func TestListConfigSessions(t *testing.T) {
 			},
 		},
 	}
-	lister := NewLister(config, mockHome, mockTmux, mockZoxide, mockTmuxinator, mockGitHub)
+	lister := NewLister(config, mockHome, mockTmux, mockZoxide, mockTmuxinator, mockGitHub, new(MockGitLab))
 
 	realLister, ok := lister.(*RealLister)
 	if !ok {
@@ -1,12 +1,15 @@
 package lister
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
-	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/joshmedeski/sesh/v2/configurator"
 	"github.com/joshmedeski/sesh/v2/github"
 	"github.com/joshmedeski/sesh/v2/model"
 )
@@ -15,18 +18,33 @@ type GitHub interface {
 	ListRepos(org string) ([]model.GitHubRepo, error)
 	ListAllRepos(config model.GitHubConfig) (map[string][]model.GitHubRepo, error)
 	ListAllReposWithRefresh(config model.GitHubConfig, refresh bool) (map[string][]model.GitHubRepo, error)
+	ListAllReposWithOptions(config model.GitHubConfig, opts FetchOptions) (map[string][]model.GitHubRepo, error)
+	ListOrgRepos(config model.GitHubConfig, org string, opts FetchOptions) ([]model.GitHubRepo, error)
 	GetAuthenticatedUsername(token string) (string, error)
 }
 
+// FetchOptions control whether the GitHub API is used
+type FetchOptions struct {
+	// Refresh revalidates every cached listing
+	Refresh bool
+	// Offline only uses the cache, even when it has expired
+	Offline bool
+}
+
 type RealGitHub struct {
-	client github.Client
-	cache  github.Cache
+	client      github.Client
+	cache       github.Cache
+	refresher   github.Refresher
+	tokens      configurator.TokenResolver
+	refreshOnce sync.Once
 }
 
-func NewGitHub(client github.Client, cache github.Cache) GitHub {
+func NewGitHub(client github.Client, cache github.Cache, refresher github.Refresher, tokens configurator.TokenResolver) GitHub {
 	return &RealGitHub{
-		client: client,
-		cache:  cache,
+		client:    client,
+		cache:     cache,
+		refresher: refresher,
+		tokens:    tokens,
 	}
 }
 
@@ -51,107 +69,305 @@ func (g *RealGitHub) ListRepos(org string) ([]model.GitHubRepo, error) {
 }
 
 func (g *RealGitHub) ListAllRepos(config model.GitHubConfig) (map[string][]model.GitHubRepo, error) {
-	return g.ListAllReposWithRefresh(config, false)
+	return g.ListAllReposWithOptions(config, FetchOptions{})
 }
 
 func (g *RealGitHub) ListAllReposWithRefresh(config model.GitHubConfig, refresh bool) (map[string][]model.GitHubRepo, error) {
+	return g.ListAllReposWithOptions(config, FetchOptions{Refresh: refresh})
+}
+
+// ListAllReposWithOptions lists the repos of every organization keyed by their
+// cache key, and the personal, starred, team and collaborator repos under the
+// cache keys of the model package
+func (g *RealGitHub) ListAllReposWithOptions(config model.GitHubConfig, opts FetchOptions) (map[string][]model.GitHubRepo, error) {
 	orgs := config.GetOrganizations()
 	results := make(map[string][]model.GitHubRepo)
-	
+
+	cacheTimeout := config.CacheTimeout
+	if cacheTimeout == 0 {
+		cacheTimeout = 30 // Default to 30 minutes
+	}
+
+	if config.IncludePersonal && !opts.Offline {
+		g.migratePersonal(config)
+	}
+
+	prefetched := make(map[string][]model.GitHubRepo)
+	if config.GraphQL && !opts.Offline {
+		prefetched = g.prefetchGraphQL(config, cacheTimeout)
+	}
+
 	for _, orgConfig := range orgs {
-		var repos []model.GitHubRepo
-		var err error
-		
-		// Try cache first unless refresh is requested
-		if !refresh {
-			if cachedRepos, found := g.cache.Get(orgConfig.Name); found {
-				results[orgConfig.Name] = cachedRepos
-				continue
-			}
-			slog.Debug("Cache miss, fetching from GitHub API", "org", orgConfig.Name)
-		} else {
-			slog.Debug("Cache refresh requested, fetching from GitHub API", "org", orgConfig.Name)
+		// Organizations on GitHub Enterprise are cached per host
+		cacheKey := orgConfig.CacheKey()
+		if repos, ok := prefetched[cacheKey]; ok {
+			results[cacheKey] = repos
+			continue
 		}
-		
+
 		// Get the appropriate token for this org
-		token := config.GetTokenForOrg(orgConfig.Name)
-		
-		// Try organization endpoint first, fall back to user endpoint if 404
-		repos, err = g.client.ListOrgReposWithToken(orgConfig.Name, token)
-		if err != nil {
-			// Check if it's a 404 error (not an organization)
-			if strings.Contains(err.Error(), "404") {
+		token := g.tokens.GitHubToken(config, orgConfig.Name)
+		client := g.client.WithBaseURL(orgConfig.GetBaseURL())
+
+		repos, err := g.fetch(cacheKey, opts, cacheTimeout, func(validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error) {
+			// Try organization endpoint first, fall back to user endpoint if it doesn't exist
+			repos, latest, err := client.ListOrgReposConditional(orgConfig.Name, token, validators)
+			if errors.Is(err, github.ErrNotFound) {
 				slog.Debug("Organization not found, trying user endpoint", "org", orgConfig.Name)
-				// Try as user instead
-				repos, err = g.client.ListUserReposWithToken(orgConfig.Name, token)
-				if err != nil {
-					slog.Error("Failed to fetch repos from GitHub (both org and user endpoints)", "org", orgConfig.Name, "error", err)
-					continue // Continue with other orgs instead of failing completely
-				}
-			} else {
-				slog.Error("Failed to fetch repos from GitHub", "org", orgConfig.Name, "error", err)
-				continue // Continue with other orgs instead of failing completely
+				return client.ListUserReposConditional(orgConfig.Name, token, validators)
 			}
+			return repos, latest, err
+		})
+		if err != nil {
+			slog.Error("Failed to fetch repos from GitHub", "org", orgConfig.Name, "error", err)
+			continue // Continue with other orgs instead of failing completely
 		}
+		results[cacheKey] = repos
+	}
 
-		// Cache the results
-		cacheTimeout := config.CacheTimeout
-		if cacheTimeout == 0 {
-			cacheTimeout = 30 // Default to 30 minutes
+	// The authenticated user's repos need a token, they're only listed with the global one
+	var token string
+	userSources := make(map[string]listFunc)
+	if config.IncludePersonal {
+		userSources[model.PersonalCacheKey] = func(validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error) {
+			return g.client.ListAuthenticatedUserReposConditional(token, validators)
+		}
+	}
+	if config.IncludeStarred {
+		userSources[model.StarredCacheKey] = func(validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error) {
+			return g.client.ListStarredReposConditional(token, validators)
 		}
-		g.cache.Set(orgConfig.Name, repos, cacheTimeout)
-		
-		results[orgConfig.Name] = repos
+	}
+	if config.IncludeCollaborator {
+		userSources[model.CollaboratorCacheKey] = func(validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error) {
+			return g.client.ListCollaboratorReposConditional(token, validators)
+		}
+	}
+	if len(userSources) > 0 {
+		token = g.tokens.GitHubToken(config, "") // Get the global token or GITHUB_TOKEN
+	}
+	for cacheKey, list := range userSources {
+		if repos, ok := prefetched[cacheKey]; ok {
+			results[cacheKey] = repos
+			continue
+		}
+		if token == "" && !opts.Offline {
+			slog.Debug("No GitHub token, skipping the authenticated user's repos", "source", cacheKey)
+			continue
+		}
+		repos, err := g.fetch(cacheKey, opts, cacheTimeout, list)
+		if err != nil {
+			slog.Error("Failed to fetch the authenticated user's repos from GitHub", "source", cacheKey, "error", err)
+			continue
+		}
+		results[cacheKey] = repos
 	}
 
-	// Include personal repos if enabled and we have a token
-	if config.IncludePersonal {
-		token := config.GetTokenForOrg("") // Get the global token or GITHUB_TOKEN
-		if token != "" {
-			// Get the authenticated user's username
-			username, err := g.GetAuthenticatedUsername(token)
+	// Teams use the token and host of their organization when it's configured
+	for _, team := range config.Teams {
+		org, slug, ok := strings.Cut(team, "/")
+		if !ok {
+			slog.Error("Invalid GitHub team, expected org/team-slug", "team", team)
+			continue
+		}
+		orgConfig, _ := config.GetOrgConfig(org)
+		teamToken := g.tokens.GitHubToken(config, org)
+		client := g.client.WithBaseURL(orgConfig.GetBaseURL())
+
+		cacheKey := model.TeamCacheKey(team)
+		repos, err := g.fetch(cacheKey, opts, cacheTimeout, func(validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error) {
+			return client.ListTeamReposConditional(org, slug, teamToken, validators)
+		})
+		if err != nil {
+			slog.Error("Failed to fetch team repos from GitHub", "team", team, "error", err)
+			continue
+		}
+		results[cacheKey] = repos
+	}
+
+	return results, nil
+}
+
+// ListOrgRepos lists the repos of one organization or user through the same
+// cache as the other listings, with the organization's config when it has one.
+// Its host, base url and token come from that config, the other sources of
+// the config aren't listed.
+func (g *RealGitHub) ListOrgRepos(config model.GitHubConfig, org string, opts FetchOptions) ([]model.GitHubRepo, error) {
+	orgConfig, ok := config.GetOrgConfig(org)
+	if !ok {
+		orgConfig = model.GitHubOrgConfig{Name: org}
+	}
+	single := config
+	single.Organization = ""
+	single.Organizations = []model.GitHubOrgConfig{orgConfig}
+	single.IncludePersonal = false
+	single.IncludeStarred = false
+	single.IncludeCollaborator = false
+	single.Teams = nil
+	results, err := g.ListAllReposWithOptions(single, opts)
+	if err != nil {
+		return nil, err
+	}
+	repos, ok := results[orgConfig.CacheKey()]
+	if !ok {
+		return nil, fmt.Errorf("couldn't list the repos of %s", org)
+	}
+	return repos, nil
+}
+
+// migratePersonal copies the personal repos that older versions of sesh cached
+// under the username to the personal cache key, so upgrading doesn't refetch
+// them. It only asks for the username while the personal key isn't cached.
+func (g *RealGitHub) migratePersonal(config model.GitHubConfig) {
+	if _, found := g.cache.Lookup(model.PersonalCacheKey); found {
+		return
+	}
+	token := g.tokens.GitHubToken(config, "")
+	if token == "" {
+		return
+	}
+	username, err := g.client.GetAuthenticatedUsername(token)
+	if err != nil || username == "" {
+		return
+	}
+	entry, found := g.cache.Lookup(username)
+	if !found || time.Now().After(entry.ExpiresAt) {
+		return
+	}
+	slog.Debug("Migrating cached personal repos", "username", username)
+	g.cache.SetWithValidators(model.PersonalCacheKey, entry.Repos, entry.CacheValidators, int(time.Until(entry.ExpiresAt).Minutes()))
+}
+
+// graphQLBatch is the owners listed with one token on one host
+type graphQLBatch struct {
+	baseURL string
+	token   string
+	owners  []string
+	// keys are the cache keys of the owners
+	keys   map[string]string
+	viewer bool
+}
+
+// prefetchGraphQL fetches the organizations and personal repos that aren't
+// cached yet with batched GraphQL queries, one batch per token and host, all
+// concurrently. Sources that fail are left to the REST API.
+func (g *RealGitHub) prefetchGraphQL(config model.GitHubConfig, cacheTimeout int) map[string][]model.GitHubRepo {
+	// Cached repos are revalidated with conditional REST requests instead,
+	// which GraphQL doesn't support
+	needed := func(cacheKey string) bool {
+		_, found := g.cache.Lookup(cacheKey)
+		return !found
+	}
+
+	batches := make(map[string]*graphQLBatch)
+	batchFor := func(baseURL, token string) *graphQLBatch {
+		key := baseURL + "\x00" + token
+		if _, ok := batches[key]; !ok {
+			batches[key] = &graphQLBatch{baseURL: baseURL, token: token, keys: make(map[string]string)}
+		}
+		return batches[key]
+	}
+	for _, orgConfig := range config.GetOrganizations() {
+		if !needed(orgConfig.CacheKey()) {
+			continue
+		}
+		batch := batchFor(orgConfig.GetBaseURL(), g.tokens.GitHubToken(config, orgConfig.Name))
+		batch.owners = append(batch.owners, orgConfig.Name)
+		batch.keys[orgConfig.Name] = orgConfig.CacheKey()
+	}
+	if config.IncludePersonal && needed(model.PersonalCacheKey) {
+		if token := g.tokens.GitHubToken(config, ""); token != "" {
+			batch := batchFor("", token)
+			batch.viewer = true
+			batch.keys[model.PersonalCacheKey] = model.PersonalCacheKey
+		}
+	}
+
+	results := make(map[string][]model.GitHubRepo)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, batch := range batches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			repos, err := g.client.WithBaseURL(batch.baseURL).ListReposGraphQL(batch.owners, batch.viewer, batch.token)
 			if err != nil {
-				slog.Error("Failed to get authenticated username for personal repos", "error", err)
-			} else {
-				// Try cache first unless refresh is requested
-				if !refresh {
-					if cachedRepos, found := g.cache.Get(username); found {
-						results[username] = cachedRepos
-					} else {
-						slog.Debug("Cache miss, fetching personal repos from GitHub API", "username", username)
-						personalRepos, err := g.fetchPersonalRepos(token)
-						if err != nil {
-							slog.Error("Failed to fetch personal repos from GitHub", "error", err)
-						} else {
-							// Cache the results
-							cacheTimeout := config.CacheTimeout
-							if cacheTimeout == 0 {
-								cacheTimeout = 30 // Default to 30 minutes
-							}
-							g.cache.Set(username, personalRepos, cacheTimeout)
-							results[username] = personalRepos
-						}
-					}
-				} else {
-					slog.Debug("Cache refresh requested, fetching personal repos from GitHub API", "username", username)
-					personalRepos, err := g.fetchPersonalRepos(token)
-					if err != nil {
-						slog.Error("Failed to fetch personal repos from GitHub", "error", err)
-					} else {
-						// Cache the results
-						cacheTimeout := config.CacheTimeout
-						if cacheTimeout == 0 {
-							cacheTimeout = 30 // Default to 30 minutes
-						}
-						g.cache.Set(username, personalRepos, cacheTimeout)
-						results[username] = personalRepos
-					}
+				slog.Error("Failed to fetch repos with GraphQL, falling back to REST", "owners", batch.owners, "error", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for owner, ownerRepos := range repos {
+				cacheKey, ok := batch.keys[owner]
+				if !ok {
+					continue
 				}
+				// GraphQL responses have no validators, the next refresh
+				// downloads the repos again
+				g.cache.SetWithValidators(cacheKey, ownerRepos, model.CacheValidators{}, cacheTimeout)
+				results[cacheKey] = ownerRepos
 			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+type listFunc func(validators model.CacheValidators) ([]model.GitHubRepo, model.CacheValidators, error)
+
+// fetch returns the cached repos of a key, expired repos are returned right
+// away while a background refresh updates the cache. Refreshing requests the
+// repos conditionally, so unchanged repos aren't downloaded again.
+func (g *RealGitHub) fetch(cacheKey string, opts FetchOptions, cacheTimeout int, list listFunc) ([]model.GitHubRepo, error) {
+	entry, found := g.cache.Lookup(cacheKey)
+	if opts.Offline {
+		if !found {
+			return nil, fmt.Errorf("no cached repos for %s while offline", cacheKey)
 		}
+		return entry.Repos, nil
 	}
 
-	return results, nil
+	if found && !opts.Refresh {
+		if time.Now().Before(entry.ExpiresAt) {
+			return entry.Repos, nil
+		}
+		slog.Debug("Cache expired, refreshing in the background", "key", cacheKey, "expired_at", entry.ExpiresAt)
+		g.refreshInBackground()
+		return entry.Repos, nil
+	}
+
+	if found {
+		slog.Debug("Cache refresh requested, revalidating with GitHub API", "key", cacheKey)
+	} else {
+		slog.Debug("Cache miss, fetching from GitHub API", "key", cacheKey)
+	}
+	repos, validators, err := list(entry.CacheValidators)
+	if errors.Is(err, github.ErrNotModified) {
+		slog.Debug("Repos not modified, extending cache", "key", cacheKey)
+		g.cache.SetWithValidators(cacheKey, entry.Repos, entry.CacheValidators, cacheTimeout)
+		return entry.Repos, nil
+	}
+	if errors.Is(err, github.ErrRateLimited) && found {
+		// The cache stays expired, so it's refreshed once the limit resets
+		slog.Warn("GitHub rate limit exceeded, using cached repos", "key", cacheKey, "error", err)
+		return entry.Repos, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	g.cache.SetWithValidators(cacheKey, repos, validators, cacheTimeout)
+	return repos, nil
+}
+
+// refreshInBackground starts at most one background refresh per run
+func (g *RealGitHub) refreshInBackground() {
+	g.refreshOnce.Do(func() {
+		if err := g.refresher.Refresh(); err != nil {
+			slog.Error("Failed to refresh GitHub cache in the background", "error", err)
+		}
+	})
 }
 
 // GetAuthenticatedUsername gets the username of the authenticated user
@@ -159,16 +375,42 @@ func (g *RealGitHub) GetAuthenticatedUsername(token string) (string, error) {
 	return g.client.GetAuthenticatedUsername(token)
 }
 
-// fetchPersonalRepos fetches repositories for the authenticated user
-func (g *RealGitHub) fetchPersonalRepos(token string) ([]model.GitHubRepo, error) {
-	return g.client.ListAuthenticatedUserReposWithToken(token)
+// githubSource is a cached listing of repos and how to name them
+type githubSource struct {
+	cacheKey string
+	host     string
+	filter   model.GitHubRepoFilter
+	prefix   string
+	// org owns every repo of an organization source, other sources have
+	// repos of several owners
+	org string
+}
+
+// name is <prefix>/<repo>, or <prefix>/<owner>/<repo> for sources with repos
+// of several owners
+func (s githubSource) name(repo model.GitHubRepo) string {
+	if s.org != "" {
+		return fmt.Sprintf("%s/%s", s.prefix, repo.Name)
+	}
+	if s.prefix == "" {
+		return repo.FullName
+	}
+	return fmt.Sprintf("%s/%s", s.prefix, repo.FullName)
+}
+
+// path is <owner>/<repo>, the clone path below the host directory
+func (s githubSource) path(repo model.GitHubRepo) string {
+	if s.org != "" {
+		return s.org + "/" + repo.Name
+	}
+	return repo.FullName
 }
 
 func listGitHub(l *RealLister, opts ListOptions) (model.SeshSessions, error) {
 	config := l.config.GitHub
 	orgs := config.GetOrganizations()
-	
-	if len(orgs) == 0 {
+
+	if !config.HasSources() {
 		slog.Debug("No GitHub organizations configured, skipping GitHub repos")
 		return model.SeshSessions{
 			Directory:    make(model.SeshSessionMap),
@@ -176,166 +418,101 @@ func listGitHub(l *RealLister, opts ListOptions) (model.SeshSessions, error) {
 		}, nil
 	}
 
-	allRepos, err := l.github.ListAllReposWithRefresh(config, opts.Refresh)
+	allRepos, err := l.github.ListAllReposWithOptions(config, FetchOptions{Refresh: opts.Refresh, Offline: opts.Offline})
 	if err != nil {
 		return model.SeshSessions{}, fmt.Errorf("couldn't list GitHub repos: %w", err)
 	}
 
 	orderedIndex := make([]string, 0)
 	directory := make(model.SeshSessionMap)
+	now := time.Now()
 
-	// Process repos from each organization
+	sources := make([]githubSource, 0, len(orgs)+len(config.Teams)+3)
 	for _, orgConfig := range orgs {
-		repos, exists := allRepos[orgConfig.Name]
-		if !exists {
-			continue
+		// Generate session names with org prefix for disambiguation
+		displayName := orgConfig.DisplayName
+		if displayName == "" {
+			displayName = orgConfig.Name
+		}
+		sources = append(sources, githubSource{orgConfig.CacheKey(), orgConfig.GetHost(), config.GetRepoFilter(orgConfig), displayName, orgConfig.Name})
+	}
+	// The other sources are prefixed with their name and the repo owner
+	sources = append(sources,
+		githubSource{model.PersonalCacheKey, model.DefaultGitHubHost, config.GitHubRepoFilter, "", ""},
+		githubSource{model.StarredCacheKey, model.DefaultGitHubHost, config.GitHubRepoFilter, "starred", ""},
+	)
+	for _, team := range config.Teams {
+		org, slug, _ := strings.Cut(team, "/")
+		orgConfig, _ := config.GetOrgConfig(org)
+		sources = append(sources, githubSource{model.TeamCacheKey(team), orgConfig.GetHost(), config.GetRepoFilter(orgConfig), "team/" + slug, ""})
+	}
+	sources = append(sources, githubSource{model.CollaboratorCacheKey, model.DefaultGitHubHost, config.GitHubRepoFilter, "collaborator", ""})
+
+	for _, source := range sources {
+		matcher, err := source.filter.Matcher(now)
+		if err != nil {
+			return model.SeshSessions{}, fmt.Errorf("couldn't filter GitHub repos of %s: %w", source.cacheKey, err)
 		}
 
-		for _, repo := range repos {
-			// Skip archived, disabled, or fork repos unless configured otherwise
-			if repo.Archived || repo.Disabled {
+		for _, repo := range allRepos[source.cacheKey] {
+			// Skip archived and disabled repos, and the ones filtered out
+			if repo.Archived || repo.Disabled || !matcher.Match(repo) {
 				continue
 			}
 
-			// Generate session name with org prefix for disambiguation
-			displayName := orgConfig.DisplayName
-			if displayName == "" {
-				displayName = orgConfig.Name
+			// A repo listed by an earlier source is skipped
+			key := fmt.Sprintf("github:%s", source.path(repo))
+			if source.host != model.DefaultGitHubHost {
+				key = fmt.Sprintf("github:%s/%s", source.host, source.path(repo))
 			}
-			
-			name := fmt.Sprintf("%s/%s", displayName, repo.Name)
+			if _, exists := directory[key]; exists {
+				continue
+			}
+
+			name := source.name(repo)
 			if repo.Description != "" && config.ShouldShowDescription() {
-				name = fmt.Sprintf("%s/%s (%s)", displayName, repo.Name, repo.Description)
+				name = fmt.Sprintf("%s (%s)", name, repo.Description)
 			}
 
-			// Determine clone path
-			cloneDir := config.CloneDir
-			if cloneDir == "" {
-				homeDir, _ := os.UserHomeDir()
-				cloneDir = filepath.Join(homeDir, "git")
-			} else if strings.HasPrefix(cloneDir, "~/") {
-				// Expand tilde to home directory
-				homeDir, _ := os.UserHomeDir()
-				cloneDir = filepath.Join(homeDir, cloneDir[2:])
+			clonePath, err := l.config.Clone.ClonePath(model.GitRemote{Host: source.host, Path: source.path(repo)}, config.CloneDir)
+			if err != nil {
+				return model.SeshSessions{}, err
+			}
+			clonePath, err = l.home.ExpandHome(clonePath)
+			if err != nil {
+				return model.SeshSessions{}, err
 			}
-			clonePath := filepath.Join(cloneDir, "github.com", orgConfig.Name, repo.Name)
 
 			// Check if repo is already cloned
-			var path string
-			var exists bool
-			if _, err := os.Stat(clonePath); err == nil {
-				path = clonePath
-				exists = true
-			} else {
-				// Use clone URL as path for uncloned repos
-				if config.UseSSH {
-					path = repo.SSHURL
-				} else {
-					path = repo.CloneURL
-				}
-			}
+			_, statErr := os.Stat(clonePath)
+			cloned := statErr == nil
 
 			// When using --github flag, show repos based on config (defaults to showing all repos)
-			if !exists && !config.ShouldShowUncloned() {
+			if !cloned && !config.ShouldShowUncloned() {
 				continue
 			}
 
-			key := fmt.Sprintf("github:%s/%s", orgConfig.Name, repo.Name)
-			orderedIndex = append(orderedIndex, key)
-			
 			session := model.SeshSession{
 				Src:  "github",
 				Name: name,
-				Path: path,
+				Path: clonePath,
 			}
 
-			// Add metadata for GitHub repos
-			if !exists {
+			if !cloned {
 				// For uncloned repos, we'll use a special startup command to clone first
-				cloneCmd := fmt.Sprintf("git clone %s %s && cd %s", path, clonePath, clonePath)
-				session.StartupCommand = cloneCmd
-				session.Path = clonePath // Update path to where it will be cloned
+				url := repo.CloneURL
+				if config.UseSSH {
+					url = repo.SSHURL
+				}
+				cloneCmd := fmt.Sprintf("git clone %s %s && cd %s", url, clonePath, clonePath)
+				session.StartupCommand = model.NewStartupCommand(cloneCmd)
 			}
 
+			orderedIndex = append(orderedIndex, key)
 			directory[key] = session
 		}
 	}
 
-	// Process personal repos if include_personal is enabled
-	if config.IncludePersonal {
-		token := config.GetTokenForOrg("") // Get the global token or GITHUB_TOKEN
-		if token != "" {
-			username, err := l.github.GetAuthenticatedUsername(token)
-			if err == nil {
-				if personalRepos, exists := allRepos[username]; exists {
-					for _, repo := range personalRepos {
-						// Skip archived, disabled, or fork repos unless configured otherwise
-						if repo.Archived || repo.Disabled {
-							continue
-						}
-
-						// Generate session name with username prefix
-						name := fmt.Sprintf("%s/%s", username, repo.Name)
-						if repo.Description != "" && config.ShouldShowDescription() {
-							name = fmt.Sprintf("%s/%s (%s)", username, repo.Name, repo.Description)
-						}
-
-						// Determine clone path
-						cloneDir := config.CloneDir
-						if cloneDir == "" {
-							homeDir, _ := os.UserHomeDir()
-							cloneDir = filepath.Join(homeDir, "git")
-						} else if strings.HasPrefix(cloneDir, "~/") {
-							// Expand tilde to home directory
-							homeDir, _ := os.UserHomeDir()
-							cloneDir = filepath.Join(homeDir, cloneDir[2:])
-						}
-						clonePath := filepath.Join(cloneDir, "github.com", username, repo.Name)
-
-						// Check if repo is already cloned
-						var path string
-						var exists bool
-						if _, err := os.Stat(clonePath); err == nil {
-							path = clonePath
-							exists = true
-						} else {
-							// Use clone URL as path for uncloned repos
-							if config.UseSSH {
-								path = repo.SSHURL
-							} else {
-								path = repo.CloneURL
-							}
-						}
-
-						// When using --github flag, show repos based on config (defaults to showing all repos)
-						if !exists && !config.ShouldShowUncloned() {
-							continue
-						}
-
-						key := fmt.Sprintf("github:%s/%s", username, repo.Name)
-						orderedIndex = append(orderedIndex, key)
-						
-						session := model.SeshSession{
-							Src:  "github",
-							Name: name,
-							Path: path,
-						}
-
-						// Add metadata for GitHub repos
-						if !exists {
-							// For uncloned repos, we'll use a special startup command to clone first
-							cloneCmd := fmt.Sprintf("git clone %s %s && cd %s", path, clonePath, clonePath)
-							session.StartupCommand = cloneCmd
-							session.Path = clonePath // Update path to where it will be cloned
-						}
-
-						directory[key] = session
-					}
-				}
-			}
-		}
-	}
-
 	return model.SeshSessions{
 		Directory:    directory,
 		OrderedIndex: orderedIndex,
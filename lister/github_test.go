@@ -0,0 +1,277 @@
+package lister
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/configurator"
+	"github.com/joshmedeski/sesh/v2/github"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// homeAt expands ~ to the directory
+func homeAt(dir string) *home.MockHome {
+	mockHome := new(home.MockHome)
+	mockHome.On("ExpandHome", mock.Anything).Return(func(path string) (string, error) {
+		return strings.Replace(path, "~", dir, 1), nil
+	})
+	return mockHome
+}
+
+// plainTokens resolves the plain tokens of a config, the global token is only
+// used on github.com
+func plainTokens() configurator.TokenResolver {
+	tokens := new(configurator.MockTokenResolver)
+	tokens.On("GitHubToken", mock.Anything, mock.Anything).Return(func(config model.GitHubConfig, org string) string {
+		orgConfig, ok := config.GetOrgConfig(org)
+		if ok && orgConfig.Token != "" {
+			return orgConfig.Token
+		}
+		if orgConfig.GetHost() != model.DefaultGitHubHost {
+			return ""
+		}
+		return config.Token
+	})
+	return tokens
+}
+
+func TestListAllReposWithOptions(t *testing.T) {
+	config := model.GitHubConfig{Organizations: []model.GitHubOrgConfig{{Name: "acme", Token: "token"}}}
+	repos := []model.GitHubRepo{{Name: "api", FullName: "acme/api"}}
+	validators := model.CacheValidators{ETag: `"v1"`}
+	expired := model.GitHubCache{Repos: repos, ExpiresAt: time.Now().Add(-time.Minute), CacheValidators: validators}
+
+	setup := func(entry model.GitHubCache, found bool) (*github.MockClient, *github.MockCache, *github.MockRefresher, GitHub) {
+		mockClient := new(github.MockClient)
+		mockCache := new(github.MockCache)
+		mockRefresher := new(github.MockRefresher)
+		mockClient.On("WithBaseURL", "").Return(mockClient)
+		mockCache.On("Lookup", "acme").Return(entry, found)
+		return mockClient, mockCache, mockRefresher, NewGitHub(mockClient, mockCache, mockRefresher, plainTokens())
+	}
+
+	t.Run("should serve expired repos while refreshing in the background", func(t *testing.T) {
+		mockClient, _, mockRefresher, gh := setup(expired, true)
+		mockRefresher.On("Refresh").Return(nil).Once()
+		results, err := gh.ListAllReposWithOptions(config, FetchOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, repos, results["acme"])
+		mockRefresher.AssertExpectations(t)
+		mockClient.AssertNotCalled(t, "ListOrgReposConditional", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should extend unchanged repos when refreshing", func(t *testing.T) {
+		mockClient, mockCache, _, gh := setup(expired, true)
+		mockClient.On("ListOrgReposConditional", "acme", "token", validators).Return(nil, validators, github.ErrNotModified)
+		mockCache.On("SetWithValidators", "acme", repos, validators, 30).Return()
+		results, err := gh.ListAllReposWithOptions(config, FetchOptions{Refresh: true})
+		assert.Nil(t, err)
+		assert.Equal(t, repos, results["acme"])
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should serve expired repos while rate limited", func(t *testing.T) {
+		mockClient, mockCache, _, gh := setup(expired, true)
+		rateLimitErr := &github.RateLimitError{RateLimit: model.GitHubRateLimit{Host: "github.com"}, Until: time.Now().Add(time.Hour)}
+		mockClient.On("ListOrgReposConditional", "acme", "token", validators).Return(nil, model.CacheValidators{}, rateLimitErr)
+		results, err := gh.ListAllReposWithOptions(config, FetchOptions{Refresh: true})
+		assert.Nil(t, err)
+		assert.Equal(t, repos, results["acme"])
+		mockCache.AssertNotCalled(t, "SetWithValidators", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should fall back to the user endpoint when the org doesn't exist", func(t *testing.T) {
+		mockClient, mockCache, _, gh := setup(model.GitHubCache{}, false)
+		notFound := fmt.Errorf("failed to list repositories for org acme: %w", github.ErrNotFound)
+		mockClient.On("ListOrgReposConditional", "acme", "token", model.CacheValidators{}).Return(nil, model.CacheValidators{}, notFound)
+		mockClient.On("ListUserReposConditional", "acme", "token", model.CacheValidators{}).Return(repos, validators, nil)
+		mockCache.On("SetWithValidators", "acme", repos, validators, 30).Return()
+		results, err := gh.ListAllReposWithOptions(config, FetchOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, repos, results["acme"])
+	})
+
+	t.Run("should list a single organization", func(t *testing.T) {
+		_, mockCache, _, gh := setup(model.GitHubCache{Repos: repos, ExpiresAt: time.Now().Add(time.Hour)}, true)
+		withStarred := config
+		withStarred.IncludeStarred = true
+		actual, err := gh.ListOrgRepos(withStarred, "acme", FetchOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, repos, actual)
+		mockCache.AssertNotCalled(t, "Lookup", model.StarredCacheKey)
+	})
+
+	t.Run("should list a single organization on its own host", func(t *testing.T) {
+		mockClient := new(github.MockClient)
+		mockCache := new(github.MockCache)
+		enterprise := new(github.MockClient)
+		mockClient.On("WithBaseURL", "https://github.example.com/api/v3/").Return(enterprise)
+		mockCache.On("Lookup", "github.example.com/acme").Return(model.GitHubCache{}, false)
+		enterprise.On("ListOrgReposConditional", "acme", "enterprise-token", model.CacheValidators{}).Return(repos, validators, nil)
+		mockCache.On("SetWithValidators", "github.example.com/acme", repos, validators, 30).Return()
+		withHost := model.GitHubConfig{
+			Token:         "token",
+			Teams:         []string{"acme/platform"},
+			Organizations: []model.GitHubOrgConfig{{Name: "acme", Host: "github.example.com", Token: "enterprise-token"}},
+		}
+
+		actual, err := NewGitHub(mockClient, mockCache, new(github.MockRefresher), plainTokens()).ListOrgRepos(withHost, "acme", FetchOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, repos, actual)
+		enterprise.AssertExpectations(t)
+		mockClient.AssertNotCalled(t, "ListTeamReposConditional", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should never call the api when offline", func(t *testing.T) {
+		mockClient, _, mockRefresher, gh := setup(model.GitHubCache{}, false)
+		results, err := gh.ListAllReposWithOptions(config, FetchOptions{Offline: true})
+		assert.Nil(t, err)
+		assert.Empty(t, results)
+		mockClient.AssertNotCalled(t, "ListOrgReposConditional", mock.Anything, mock.Anything, mock.Anything)
+		mockRefresher.AssertNotCalled(t, "Refresh")
+	})
+}
+
+func TestListAllReposWithGraphQL(t *testing.T) {
+	config := model.GitHubConfig{
+		Token:           "token",
+		GraphQL:         true,
+		IncludePersonal: true,
+		Organizations: []model.GitHubOrgConfig{
+			{Name: "acme"},
+			{Name: "cached"},
+			{Name: "enterprise", Host: "github.example.com", Token: "enterprise-token"},
+		},
+	}
+	acme := []model.GitHubRepo{{Name: "api", FullName: "acme/api"}}
+	personal := []model.GitHubRepo{{Name: "dotfiles", FullName: "wile/dotfiles"}}
+	enterprise := []model.GitHubRepo{{Name: "infra", FullName: "enterprise/infra"}}
+	cached := model.GitHubCache{Repos: []model.GitHubRepo{{Name: "web", FullName: "cached/web"}}, ExpiresAt: time.Now().Add(time.Hour)}
+
+	mockClient := new(github.MockClient)
+	mockEnterpriseClient := new(github.MockClient)
+	mockCache := new(github.MockCache)
+	mockClient.On("WithBaseURL", "").Return(mockClient)
+	mockClient.On("WithBaseURL", "https://github.example.com/api/v3/").Return(mockEnterpriseClient)
+	mockCache.On("Lookup", "cached").Return(cached, true)
+	mockCache.On("Lookup", mock.Anything).Return(model.GitHubCache{}, false)
+	mockCache.On("SetWithValidators", mock.Anything, mock.Anything, model.CacheValidators{}, 30).Return()
+	mockClient.On("GetAuthenticatedUsername", "token").Return("wile", nil).Once()
+	mockClient.On("ListReposGraphQL", []string{"acme"}, true, "token").
+		Return(map[string][]model.GitHubRepo{"acme": acme, model.PersonalCacheKey: personal}, nil).Once()
+	mockEnterpriseClient.On("ListReposGraphQL", []string{"enterprise"}, false, "enterprise-token").
+		Return(map[string][]model.GitHubRepo{"enterprise": enterprise}, nil).Once()
+
+	results, err := NewGitHub(mockClient, mockCache, new(github.MockRefresher), plainTokens()).ListAllReposWithOptions(config, FetchOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, acme, results["acme"])
+	assert.Equal(t, personal, results[model.PersonalCacheKey])
+	assert.Equal(t, enterprise, results["github.example.com/enterprise"])
+	assert.Equal(t, cached.Repos, results["cached"])
+	mockClient.AssertExpectations(t)
+	mockEnterpriseClient.AssertExpectations(t)
+	mockCache.AssertCalled(t, "SetWithValidators", "github.example.com/enterprise", enterprise, model.CacheValidators{}, 30)
+	mockClient.AssertNotCalled(t, "ListOrgReposConditional", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefreshWithGraphQL(t *testing.T) {
+	config := model.GitHubConfig{
+		GraphQL:       true,
+		Organizations: []model.GitHubOrgConfig{{Name: "acme", Token: "token"}, {Name: "new", Token: "token"}},
+	}
+	repos := []model.GitHubRepo{{Name: "api", FullName: "acme/api"}}
+	fresh := []model.GitHubRepo{{Name: "web", FullName: "new/web"}}
+	validators := model.CacheValidators{ETag: `"v1"`}
+	expired := model.GitHubCache{Repos: repos, ExpiresAt: time.Now().Add(-time.Minute), CacheValidators: validators}
+
+	mockClient := new(github.MockClient)
+	mockCache := new(github.MockCache)
+	mockClient.On("WithBaseURL", "").Return(mockClient)
+	mockCache.On("Lookup", "acme").Return(expired, true)
+	mockCache.On("Lookup", "new").Return(model.GitHubCache{}, false)
+	mockClient.On("ListReposGraphQL", []string{"new"}, false, "token").Return(map[string][]model.GitHubRepo{"new": fresh}, nil).Once()
+	mockCache.On("SetWithValidators", "new", fresh, model.CacheValidators{}, 30).Return()
+	mockClient.On("ListOrgReposConditional", "acme", "token", validators).Return(nil, validators, github.ErrNotModified).Once()
+	mockCache.On("SetWithValidators", "acme", repos, validators, 30).Return()
+
+	results, err := NewGitHub(mockClient, mockCache, new(github.MockRefresher), plainTokens()).ListAllReposWithOptions(config, FetchOptions{Refresh: true})
+	assert.Nil(t, err)
+	assert.Equal(t, repos, results["acme"])
+	assert.Equal(t, fresh, results["new"])
+	mockClient.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestListGitHubSources(t *testing.T) {
+	homeDir := "/home/test"
+	config := model.GitHubConfig{
+		Organizations:  []model.GitHubOrgConfig{{Name: "acme", DisplayName: "Acme"}},
+		IncludeStarred: true,
+		Teams:          []string{"acme/platform"},
+	}
+	repo := func(fullName string) model.GitHubRepo {
+		_, name, _ := strings.Cut(fullName, "/")
+		return model.GitHubRepo{Name: name, FullName: fullName, CloneURL: "https://github.com/" + fullName + ".git"}
+	}
+
+	mockGitHub := new(MockGitHub)
+	mockGitHub.On("ListAllReposWithOptions", config, FetchOptions{}).Return(map[string][]model.GitHubRepo{
+		"acme":                              {repo("acme/api")},
+		model.StarredCacheKey:               {repo("golang/go"), repo("acme/api")},
+		model.TeamCacheKey("acme/platform"): {repo("acme/infra")},
+	}, nil)
+
+	lister := &RealLister{config: model.Config{GitHub: config}, home: homeAt(homeDir), github: mockGitHub}
+	sessions, err := listGitHub(lister, ListOptions{GitHub: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"github:acme/api", "github:golang/go", "github:acme/infra"}, sessions.OrderedIndex)
+	assert.Equal(t, "Acme/api", sessions.Directory["github:acme/api"].Name)
+	assert.Equal(t, "starred/golang/go", sessions.Directory["github:golang/go"].Name)
+	assert.Equal(t, "team/platform/acme/infra", sessions.Directory["github:acme/infra"].Name)
+	assert.Equal(t, filepath.Join(homeDir, "git", "github.com", "golang", "go"), sessions.Directory["github:golang/go"].Path)
+}
+
+func TestMigratePersonal(t *testing.T) {
+	config := model.GitHubConfig{Token: "token", IncludePersonal: true}
+	repos := []model.GitHubRepo{{Name: "dotfiles", FullName: "wile/dotfiles"}}
+	validators := model.CacheValidators{ETag: "etag"}
+
+	t.Run("should copy the repos cached under the username", func(t *testing.T) {
+		mockClient := new(github.MockClient)
+		mockCache := new(github.MockCache)
+		mockCache.On("Lookup", model.PersonalCacheKey).Return(model.GitHubCache{}, false)
+		mockCache.On("Lookup", "wile").Return(model.GitHubCache{Repos: repos, CacheValidators: validators, ExpiresAt: time.Now().Add(time.Hour)}, true)
+		mockCache.On("SetWithValidators", model.PersonalCacheKey, repos, validators, mock.Anything).Return()
+		mockClient.On("GetAuthenticatedUsername", "token").Return("wile", nil)
+
+		NewGitHub(mockClient, mockCache, new(github.MockRefresher), plainTokens()).(*RealGitHub).migratePersonal(config)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should not ask for the username once migrated", func(t *testing.T) {
+		mockClient := new(github.MockClient)
+		mockCache := new(github.MockCache)
+		mockCache.On("Lookup", model.PersonalCacheKey).Return(model.GitHubCache{Repos: repos}, true)
+
+		NewGitHub(mockClient, mockCache, new(github.MockRefresher), plainTokens()).(*RealGitHub).migratePersonal(config)
+		mockClient.AssertNotCalled(t, "GetAuthenticatedUsername", mock.Anything)
+		mockCache.AssertNotCalled(t, "SetWithValidators", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should skip expired entries", func(t *testing.T) {
+		mockClient := new(github.MockClient)
+		mockCache := new(github.MockCache)
+		mockCache.On("Lookup", model.PersonalCacheKey).Return(model.GitHubCache{}, false)
+		mockCache.On("Lookup", "wile").Return(model.GitHubCache{Repos: repos, ExpiresAt: time.Now().Add(-time.Hour)}, true)
+		mockClient.On("GetAuthenticatedUsername", "token").Return("wile", nil)
+
+		NewGitHub(mockClient, mockCache, new(github.MockRefresher), plainTokens()).(*RealGitHub).migratePersonal(config)
+		mockCache.AssertNotCalled(t, "SetWithValidators", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,130 @@
+package lister
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/joshmedeski/sesh/v2/gitlab"
+	"github.com/joshmedeski/sesh/v2/model"
+)
+
+type GitLab interface {
+	ListAllProjects(config model.GitLabConfig, refresh bool) (map[string][]model.GitLabProject, error)
+}
+
+type RealGitLab struct {
+	client gitlab.Client
+	cache  gitlab.Cache
+}
+
+func NewGitLab(client gitlab.Client, cache gitlab.Cache) GitLab {
+	return &RealGitLab{
+		client: client,
+		cache:  cache,
+	}
+}
+
+// ListAllProjects lists the projects of every configured group, keyed by group
+func (g *RealGitLab) ListAllProjects(config model.GitLabConfig, refresh bool) (map[string][]model.GitLabProject, error) {
+	results := make(map[string][]model.GitLabProject)
+
+	for _, group := range config.Groups {
+		cacheKey := config.GetHost() + "/" + group
+
+		if !refresh {
+			if cachedProjects, found := g.cache.Get(cacheKey); found {
+				results[group] = cachedProjects
+				continue
+			}
+			slog.Debug("Cache miss, fetching from GitLab API", "group", group)
+		} else {
+			slog.Debug("Cache refresh requested, fetching from GitLab API", "group", group)
+		}
+
+		projects, err := g.client.ListGroupProjects(group, config.ShouldIncludeSubgroups())
+		if err != nil {
+			slog.Error("Failed to fetch projects from GitLab", "group", group, "error", err)
+			continue // Continue with other groups instead of failing completely
+		}
+
+		g.cache.Set(cacheKey, projects, config.GetCacheTimeout())
+		results[group] = projects
+	}
+
+	return results, nil
+}
+
+func listGitLab(l *RealLister, opts ListOptions) (model.SeshSessions, error) {
+	config := l.config.GitLab
+	orderedIndex := make([]string, 0)
+	directory := make(model.SeshSessionMap)
+
+	if len(config.Groups) == 0 {
+		slog.Debug("No GitLab groups configured, skipping GitLab projects")
+		return model.SeshSessions{Directory: directory, OrderedIndex: orderedIndex}, nil
+	}
+
+	allProjects, err := l.gitlab.ListAllProjects(config, opts.Refresh)
+	if err != nil {
+		return model.SeshSessions{}, fmt.Errorf("couldn't list GitLab projects: %w", err)
+	}
+
+	for _, group := range config.Groups {
+		for _, project := range allProjects[group] {
+			if project.Archived {
+				continue
+			}
+
+			key := "gitlab:" + project.PathWithNamespace
+			if _, exists := directory[key]; exists {
+				continue // listed by a parent group
+			}
+
+			clonePath, err := l.config.Clone.ClonePath(model.GitRemote{Host: config.GetHost(), Path: project.PathWithNamespace}, config.CloneDir)
+			if err != nil {
+				return model.SeshSessions{}, err
+			}
+			clonePath, err = l.home.ExpandHome(clonePath)
+			if err != nil {
+				return model.SeshSessions{}, err
+			}
+			session := model.SeshSession{
+				Src:  "gitlab",
+				Name: project.PathWithNamespace,
+				Path: clonePath,
+			}
+
+			if _, err := os.Stat(clonePath); err != nil {
+				// For uncloned projects, the connector clones them first
+				url := project.HTTPURLToRepo
+				if config.UseSSH {
+					url = project.SSHURLToRepo
+				}
+				session.StartupCommand = model.NewStartupCommand(fmt.Sprintf("git clone %s %s && cd %s", url, clonePath, clonePath))
+			}
+
+			orderedIndex = append(orderedIndex, key)
+			directory[key] = session
+		}
+	}
+
+	return model.SeshSessions{
+		Directory:    directory,
+		OrderedIndex: orderedIndex,
+	}, nil
+}
+
+func (l *RealLister) FindGitLabSession(name string) (model.SeshSession, bool) {
+	sessions, err := listGitLab(l, ListOptions{GitLab: true})
+	if err != nil {
+		return model.SeshSession{}, false
+	}
+	if session, exists := sessions.Directory["gitlab:"+name]; exists {
+		return session, true
+	}
+	if session, exists := sessions.Directory[name]; exists {
+		return session, true
+	}
+	return model.SeshSession{}, false
+}
@@ -0,0 +1,39 @@
+package lister
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/gitlab"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListGitLab(t *testing.T) {
+	homeDir := "/home/test"
+	config := model.GitLabConfig{URL: "https://gitlab.example.com", Groups: []string{"acme", "acme/platform"}}
+	projects := []model.GitLabProject{
+		{PathWithNamespace: "acme/platform/api", HTTPURLToRepo: "https://gitlab.example.com/acme/platform/api.git"},
+		{PathWithNamespace: "acme/legacy", Archived: true},
+	}
+
+	mockClient := new(gitlab.MockClient)
+	mockCache := new(gitlab.MockCache)
+	mockCache.On("Get", "gitlab.example.com/acme").Return(projects, true)
+	mockCache.On("Get", "gitlab.example.com/acme/platform").Return(nil, false)
+	mockClient.On("ListGroupProjects", "acme/platform", true).Return(projects[:1], nil)
+	mockCache.On("Set", "gitlab.example.com/acme/platform", projects[:1], 30).Return()
+
+	lister := &RealLister{config: model.Config{GitLab: config}, home: homeAt(homeDir), gitlab: NewGitLab(mockClient, mockCache)}
+	sessions, err := listGitLab(lister, ListOptions{GitLab: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"gitlab:acme/platform/api"}, sessions.OrderedIndex)
+
+	clonePath := filepath.Join(homeDir, "git", "gitlab.example.com", "acme", "platform", "api")
+	session := sessions.Directory["gitlab:acme/platform/api"]
+	assert.Equal(t, "gitlab", session.Src)
+	assert.Equal(t, clonePath, session.Path)
+	assert.Equal(t, "git clone https://gitlab.example.com/acme/platform/api.git "+clonePath+" && cd "+clonePath, session.StartupCommand.String())
+	mockClient.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
@@ -14,6 +14,8 @@ type (
 		Zoxide         bool
 		Tmuxinator     bool
 		GitHub         bool
+		GitLab         bool
+		Offline        bool
 		HideDuplicates bool
 		Refresh        bool
 	}
@@ -26,6 +28,7 @@ var srcStrategies = map[string]srcStrategy{
 	"tmuxinator": listTmuxinator,
 	"zoxide":     listZoxide,
 	"github":     listGitHub,
+	"gitlab":     listGitLab,
 }
 
 func (l *RealLister) List(opts ListOptions) (model.SeshSessions, error) {
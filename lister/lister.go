@@ -17,6 +17,7 @@ type Lister interface {
 	FindZoxideSession(name string) (model.SeshSession, bool)
 	FindTmuxinatorConfig(name string) (model.SeshSession, bool)
 	FindGitHubSession(name string) (model.SeshSession, bool)
+	FindGitLabSession(name string) (model.SeshSession, bool)
 }
 
 type RealLister struct {
@@ -26,8 +27,9 @@ type RealLister struct {
 	zoxide     zoxide.Zoxide
 	tmuxinator tmuxinator.Tmuxinator
 	github     GitHub
+	gitlab     GitLab
 }
 
-func NewLister(config model.Config, home home.Home, tmux tmux.Tmux, zoxide zoxide.Zoxide, tmuxinator tmuxinator.Tmuxinator, github GitHub) Lister {
-	return &RealLister{config, home, tmux, zoxide, tmuxinator, github}
+func NewLister(config model.Config, home home.Home, tmux tmux.Tmux, zoxide zoxide.Zoxide, tmuxinator tmuxinator.Tmuxinator, github GitHub, gitlab GitLab) Lister {
+	return &RealLister{config, home, tmux, zoxide, tmuxinator, github, gitlab}
 }
@@ -48,6 +48,9 @@ func srcs(opts ListOptions) []string {
 	if opts.GitHub {
 		count++
 	}
+	if opts.GitLab {
+		count++
+	}
 	if count == 0 {
 		return []string{"tmux", "config", "tmuxinator", "zoxide"}
 	}
@@ -73,5 +76,9 @@ func srcs(opts ListOptions) []string {
 		srcs[i] = "github"
 		i++
 	}
+	if opts.GitLab {
+		srcs[i] = "gitlab"
+		i++
+	}
 	return srcs
 }
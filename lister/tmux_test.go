@@ -78,7 +78,7 @@ func TestListTmuxSessions(t *testing.T) {
 		mockZoxide := new(zoxide.MockZoxide)
 		mockTmuxinator := new(tmuxinator.MockTmuxinator)
 		mockGitHub := &MockGitHub{}
-		lister := NewLister(mockConfig, mockHome, mockTmux, mockZoxide, mockTmuxinator, mockGitHub)
+		lister := NewLister(mockConfig, mockHome, mockTmux, mockZoxide, mockTmuxinator, mockGitHub, new(MockGitLab))
 
 		realLister, ok := lister.(*RealLister)
 		if !ok {
@@ -103,7 +103,7 @@ func TestListTmuxSessionsError(t *testing.T) {
 		mockZoxide := new(zoxide.MockZoxide)
 		mockTmuxinator := new(tmuxinator.MockTmuxinator)
 		mockGitHub := &MockGitHub{}
-		lister := NewLister(mockConfig, mockHome, mockTmux, mockZoxide, mockTmuxinator, mockGitHub)
+		lister := NewLister(mockConfig, mockHome, mockTmux, mockZoxide, mockTmuxinator, mockGitHub, new(MockGitLab))
 
 		realLister, ok := lister.(*RealLister)
 		if !ok {
@@ -183,7 +183,7 @@ func TestBlacklistHidesTmuxSession(t *testing.T) {
 		mockZoxide := new(zoxide.MockZoxide)
 		mockTmuxinator := new(tmuxinator.MockTmuxinator)
 		mockGitHub := &MockGitHub{}
-		lister := NewLister(mockConfig, mockHome, mockTmux, mockZoxide, mockTmuxinator, mockGitHub)
+		lister := NewLister(mockConfig, mockHome, mockTmux, mockZoxide, mockTmuxinator, mockGitHub, new(MockGitLab))
 
 		realLister, ok := lister.(*RealLister)
 		if !ok {
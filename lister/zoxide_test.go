@@ -33,7 +33,7 @@ func TestListZoxideSessions(t *testing.T) {
 		}, nil)
 
 		mockGitHub := &MockGitHub{}
-		lister := NewLister(mockConfig, mockHome, mockTmux, mockZoxide, mockTmuxinator, mockGitHub)
+		lister := NewLister(mockConfig, mockHome, mockTmux, mockZoxide, mockTmuxinator, mockGitHub, new(MockGitLab))
 
 		realLister, ok := lister.(*RealLister)
 		if !ok {
@@ -0,0 +1,150 @@
+package localconfig
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/joshmedeski/sesh/v2/pathwrap"
+)
+
+// FileName is the name of the repo-local config file
+const FileName = ".sesh.toml"
+
+type LocalConfig interface {
+	// Loads the trusted repo-local config for a session path
+	Load(path string) (model.LocalConfig, bool, error)
+	// Trusts the repo-local config in the given directory
+	Trust(dir string) (string, error)
+}
+
+type loadResult struct {
+	config model.LocalConfig
+	found  bool
+}
+
+type RealLocalConfig struct {
+	config model.Config
+	os     oswrap.Os
+	home   home.Home
+	git    git.Git
+	trust  TrustStore
+	loaded map[string]loadResult // Load results per path, so the trust prompt is shown only once
+}
+
+func NewLocalConfig(config model.Config, os oswrap.Os, home home.Home, git git.Git, trust TrustStore) LocalConfig {
+	return &RealLocalConfig{config, os, home, git, trust, make(map[string]loadResult)}
+}
+
+func (l *RealLocalConfig) Load(path string) (model.LocalConfig, bool, error) {
+	if !l.config.LocalConfig.Enabled {
+		return model.LocalConfig{}, false, nil
+	}
+	if result, exists := l.loaded[path]; exists {
+		return result.config, result.found, nil
+	}
+
+	for _, dir := range l.candidateDirs(path) {
+		file := filepath.Join(dir, FileName)
+		raw, err := l.os.ReadFile(file)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return model.LocalConfig{}, false, fmt.Errorf("couldn't read %s: %w", file, err)
+		}
+
+		local, err := decode(raw)
+		if err != nil {
+			return model.LocalConfig{}, false, fmt.Errorf("couldn't parse %s: %w", file, err)
+		}
+
+		trusted := l.isTrustedDir(dir) || l.trust.IsTrusted(file, raw)
+		if !trusted && l.confirm(file, raw) {
+			if err := l.trust.Allow(file, raw); err != nil {
+				return model.LocalConfig{}, false, err
+			}
+			trusted = true
+		}
+		if !trusted {
+			slog.Warn("Ignoring untrusted local config, run `sesh config trust` to allow it", "file", file)
+			break
+		}
+
+		l.loaded[path] = loadResult{local, true}
+		return local, true, nil
+	}
+
+	l.loaded[path] = loadResult{}
+	return model.LocalConfig{}, false, nil
+}
+
+func (l *RealLocalConfig) Trust(dir string) (string, error) {
+	file := filepath.Join(dir, FileName)
+	raw, err := l.os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read %s: %w", file, err)
+	}
+	if _, err := decode(raw); err != nil {
+		return "", fmt.Errorf("couldn't parse %s: %w", file, err)
+	}
+	if err := l.trust.Allow(file, raw); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+// candidateDirs returns the session path followed by its git root (if different)
+func (l *RealLocalConfig) candidateDirs(path string) []string {
+	dirs := []string{path}
+	if isGit, topLevel, _ := l.git.ShowTopLevel(path); isGit && topLevel != "" && topLevel != path {
+		dirs = append(dirs, topLevel)
+	}
+	return dirs
+}
+
+func (l *RealLocalConfig) isTrustedDir(dir string) bool {
+	for _, pattern := range l.config.LocalConfig.TrustedPaths {
+		expanded, err := l.home.ExpandHome(pattern)
+		if err != nil {
+			continue
+		}
+		if matched, _ := pathwrap.MatchGlob(expanded, dir); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// confirm asks the user whether to trust the file, when sesh runs in a terminal
+func (l *RealLocalConfig) confirm(file string, raw []byte) bool {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "%s wants to run commands:\n\n%s\n\nTrust this file? [y/N] ", file, strings.TrimSpace(string(raw)))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func decode(raw []byte) (model.LocalConfig, error) {
+	local := model.LocalConfig{}
+	d := toml.NewDecoder(strings.NewReader(string(raw)))
+	d.DisallowUnknownFields()
+	d.EnableUnmarshalerInterface()
+	if err := d.Decode(&local); err != nil {
+		return model.LocalConfig{}, err
+	}
+	return local, nil
+}
@@ -0,0 +1,88 @@
+package localconfig
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const localFile = `startup_command = "npm run dev"
+windows = ["server"]
+
+[[window]]
+name = "server"
+startup_script = "npm start"
+`
+
+func TestLoad(t *testing.T) {
+	setup := func(config model.Config) (*RealLocalConfig, *oswrap.MockOs, *MockTrustStore) {
+		mockOs := new(oswrap.MockOs)
+		mockHome := new(home.MockHome)
+		mockGit := new(git.MockGit)
+		mockTrust := new(MockTrustStore)
+		mockHome.On("ExpandHome", "~/c/*").Return("/Users/josh/c/*", nil)
+		mockGit.On("ShowTopLevel", mock.Anything).Return(false, "", fmt.Errorf("not a git repository"))
+		l := NewLocalConfig(config, mockOs, mockHome, mockGit, mockTrust).(*RealLocalConfig)
+		return l, mockOs, mockTrust
+	}
+	enabled := model.Config{LocalConfig: model.LocalConfigSettings{Enabled: true, TrustedPaths: []string{"~/c/*"}}}
+
+	t.Run("should ignore local config when disabled", func(t *testing.T) {
+		l, _, _ := setup(model.Config{})
+		_, found, err := l.Load("/Users/josh/c/sesh")
+		assert.Nil(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("should load local config from a trusted path", func(t *testing.T) {
+		l, mockOs, mockTrust := setup(enabled)
+		mockOs.On("ReadFile", "/Users/josh/c/sesh/.sesh.toml").Return([]byte(localFile), nil)
+		local, found, err := l.Load("/Users/josh/c/sesh")
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, model.NewStartupCommand("npm run dev"), local.StartupCommand)
+		assert.Equal(t, []string{"server"}, local.Windows)
+		assert.Equal(t, "npm start", local.WindowConfigs[0].StartupScript)
+		mockTrust.AssertNotCalled(t, "IsTrusted", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should load local config trusted by the allow-list", func(t *testing.T) {
+		l, mockOs, mockTrust := setup(enabled)
+		mockOs.On("ReadFile", "/tmp/project/.sesh.toml").Return([]byte(localFile), nil)
+		mockTrust.On("IsTrusted", "/tmp/project/.sesh.toml", []byte(localFile)).Return(true)
+		_, found, err := l.Load("/tmp/project")
+		assert.Nil(t, err)
+		assert.True(t, found)
+	})
+
+	t.Run("should ignore untrusted local config", func(t *testing.T) {
+		l, mockOs, mockTrust := setup(enabled)
+		mockOs.On("ReadFile", "/tmp/project/.sesh.toml").Return([]byte(localFile), nil)
+		mockTrust.On("IsTrusted", "/tmp/project/.sesh.toml", []byte(localFile)).Return(false)
+		_, found, err := l.Load("/tmp/project")
+		assert.Nil(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("should skip directories without local config", func(t *testing.T) {
+		l, mockOs, _ := setup(enabled)
+		mockOs.On("ReadFile", "/Users/josh/c/empty/.sesh.toml").Return(nil, os.ErrNotExist)
+		_, found, err := l.Load("/Users/josh/c/empty")
+		assert.Nil(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("should reject unknown fields", func(t *testing.T) {
+		l, mockOs, _ := setup(enabled)
+		mockOs.On("ReadFile", "/Users/josh/c/typo/.sesh.toml").Return([]byte(`startup_comand = "ls"`), nil)
+		_, _, err := l.Load("/Users/josh/c/typo")
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,93 @@
+package localconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+)
+
+// TrustStore is the allow-list of repo-local config files. A file is trusted
+// by its content, so any change to it has to be trusted again.
+type TrustStore interface {
+	IsTrusted(file string, content []byte) bool
+	Allow(file string, content []byte) error
+}
+
+type RealTrustStore struct {
+	os   oswrap.Os
+	home home.Home
+}
+
+func NewTrustStore(os oswrap.Os, home home.Home) TrustStore {
+	return &RealTrustStore{os, home}
+}
+
+func (t *RealTrustStore) IsTrusted(file string, content []byte) bool {
+	trusted := t.read()
+	hash, exists := trusted[file]
+	return exists && hash == contentHash(content)
+}
+
+func (t *RealTrustStore) Allow(file string, content []byte) error {
+	storePath, err := t.storePath()
+	if err != nil {
+		return err
+	}
+
+	trusted := t.read()
+	trusted[file] = contentHash(content)
+
+	data, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal trusted files: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(storePath), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if err := os.WriteFile(storePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write trusted files: %w", err)
+	}
+	return nil
+}
+
+func (t *RealTrustStore) read() map[string]string {
+	trusted := make(map[string]string)
+	storePath, err := t.storePath()
+	if err != nil {
+		return trusted
+	}
+	data, err := t.os.ReadFile(storePath)
+	if err != nil {
+		return trusted
+	}
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		slog.Warn("Failed to unmarshal trusted files", "path", storePath, "error", err)
+		return make(map[string]string)
+	}
+	return trusted
+}
+
+// storePath returns $XDG_DATA_HOME/sesh/trusted.json, defaulting to ~/.local/share
+func (t *RealTrustStore) storePath() (string, error) {
+	dataHome := t.os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		var err error
+		dataHome, err = t.home.ExpandHome("~/.local/share")
+		if err != nil {
+			return "", fmt.Errorf("couldn't get data dir: %w", err)
+		}
+	}
+	return filepath.Join(dataHome, "sesh", "trusted.json"), nil
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
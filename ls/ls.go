@@ -1,7 +1,10 @@
 package ls
 
 import (
+	"path/filepath"
+
 	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/replacer"
 	"github.com/joshmedeski/sesh/v2/shell"
 )
 
@@ -10,12 +13,13 @@ type Ls interface {
 }
 
 type RealLs struct {
-	config model.Config
-	shell  shell.Shell
+	config   model.Config
+	shell    shell.Shell
+	replacer replacer.Replacer
 }
 
-func NewLs(config model.Config, shell shell.Shell) Ls {
-	return &RealLs{config, shell}
+func NewLs(config model.Config, shell shell.Shell, replacer replacer.Replacer) Ls {
+	return &RealLs{config, shell, replacer}
 }
 
 func (g *RealLs) ListDirectory(path string) (string, error) {
@@ -24,6 +28,11 @@ func (g *RealLs) ListDirectory(path string) (string, error) {
 		command = "ls {}"
 	}
 
+	command, err := g.replacer.Render(command, model.SeshSession{Name: filepath.Base(path), Path: path})
+	if err != nil {
+		return "", err
+	}
+
 	cmdParts, err := g.shell.PrepareCmd(command, map[string]string{"{}": path})
 	if err != nil {
 		return "", err
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// DefaultClonePathTemplate clones into <clone_dir>/<host>/<path>
+const DefaultClonePathTemplate = "{{.CloneDir}}/{{.Host}}/{{.Path}}"
+
+// GitRemote is a repository url reduced to its host and namespace path
+type GitRemote struct {
+	// Host is the host name without a port
+	Host string
+	// Path is the full namespace path without .git, e.g. group/subgroup/repo
+	Path string
+}
+
+// ClonePathData are the fields of clone path templates
+type ClonePathData struct {
+	Host     string
+	Path     string
+	Owner    string
+	Repo     string
+	CloneDir string
+}
+
+// ParseGitURL parses https, ssh and scp-like (git@host:path) urls
+func ParseGitURL(rawURL string) (GitRemote, error) {
+	var host, path string
+	if !strings.Contains(rawURL, "://") {
+		// scp-like syntax: [user@]host:path
+		userHost, rest, ok := strings.Cut(rawURL, ":")
+		if !ok {
+			return GitRemote{}, fmt.Errorf("invalid git url %q", rawURL)
+		}
+		_, host, _ = strings.Cut(userHost, "@")
+		if host == "" {
+			host = userHost
+		}
+		path = rest
+	} else {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return GitRemote{}, fmt.Errorf("invalid git url %q: %w", rawURL, err)
+		}
+		host = u.Hostname()
+		path = u.Path
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return GitRemote{}, fmt.Errorf("invalid git url %q, expected a host and a path", rawURL)
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return GitRemote{}, fmt.Errorf("invalid git url %q", rawURL)
+		}
+	}
+	return GitRemote{Host: host, Path: path}, nil
+}
+
+// SameRemote reports whether two urls point at the same repository, no matter
+// the protocol, user, port or .git suffix. Local paths have to be the same.
+func SameRemote(a, b string) bool {
+	remoteA, errA := ParseGitURL(a)
+	remoteB, errB := ParseGitURL(b)
+	if errA != nil || errB != nil {
+		trim := func(path string) string { return strings.TrimSuffix(strings.TrimRight(path, "/"), ".git") }
+		return trim(a) == trim(b)
+	}
+	return strings.EqualFold(remoteA.Host, remoteB.Host) && strings.EqualFold(remoteA.Path, remoteB.Path)
+}
+
+// Data returns the template fields of the remote
+func (r GitRemote) Data(cloneDir string) ClonePathData {
+	owner, repo := "", r.Path
+	if i := strings.LastIndex(r.Path, "/"); i >= 0 {
+		owner, repo = r.Path[:i], r.Path[i+1:]
+	}
+	return ClonePathData{Host: r.Host, Path: r.Path, Owner: owner, Repo: repo, CloneDir: cloneDir}
+}
+
+// HostPathTemplate returns the clone path template of a host
+func (c CloneConfig) HostPathTemplate(host string) string {
+	if tmpl, ok := c.Hosts[host]; ok && tmpl != "" {
+		return tmpl
+	}
+	if c.PathTemplate != "" {
+		return c.PathTemplate
+	}
+	return DefaultClonePathTemplate
+}
+
+// ClonePath returns where to clone a repository, cloneDir is the clone_dir of
+// the source, ~/git by default. The path may start with ~, callers expand it.
+func (c CloneConfig) ClonePath(remote GitRemote, cloneDir string) (string, error) {
+	if cloneDir == "" {
+		cloneDir = "~/git"
+	}
+	tmpl, err := template.New("path_template").Option("missingkey=error").Parse(c.HostPathTemplate(remote.Host))
+	if err != nil {
+		return "", fmt.Errorf("invalid clone path template for %s: %w", remote.Host, err)
+	}
+	var path strings.Builder
+	if err := tmpl.Execute(&path, remote.Data(strings.TrimSuffix(cloneDir, "/"))); err != nil {
+		return "", fmt.Errorf("invalid clone path template for %s: %w", remote.Host, err)
+	}
+	return filepath.Clean(filepath.FromSlash(path.String())), nil
+}
+
+// ExpandAlias expands a configured alias, e.g. gl:group/repo to the url of
+// gl = "git@gitlab.com:{{.Path}}.git", other input is returned as is
+func (c CloneConfig) ExpandAlias(input string) (string, bool, error) {
+	alias, path, ok := strings.Cut(input, ":")
+	if !ok {
+		return input, false, nil
+	}
+	urlTemplate, ok := c.Aliases[alias]
+	if !ok || strings.HasPrefix(path, "//") {
+		return input, false, nil
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if path == "" {
+		return "", false, fmt.Errorf("missing repository path after %s:", alias)
+	}
+	tmpl, err := template.New("alias").Option("missingkey=error").Parse(urlTemplate)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid clone alias %s: %w", alias, err)
+	}
+	var repoURL strings.Builder
+	if err := tmpl.Execute(&repoURL, GitRemote{Path: path}.Data("")); err != nil {
+		return "", false, fmt.Errorf("invalid clone alias %s: %w", alias, err)
+	}
+	return repoURL.String(), true, nil
+}
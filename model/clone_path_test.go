@@ -0,0 +1,107 @@
+package model
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGitURL(t *testing.T) {
+	tests := map[string]GitRemote{
+		"https://github.com/joshmedeski/sesh.git":            {Host: "github.com", Path: "joshmedeski/sesh"},
+		"https://gitlab.com/group/sub/deeper/repo":           {Host: "gitlab.com", Path: "group/sub/deeper/repo"},
+		"git@gitlab.com:group/sub/repo.git":                  {Host: "gitlab.com", Path: "group/sub/repo"},
+		"ssh://git@git.corp:7999/team/service.git":           {Host: "git.corp", Path: "team/service"},
+		"gitlab.example.com:platform/api.git":                {Host: "gitlab.example.com", Path: "platform/api"},
+		"https://user@bitbucket.org/workspace/project/repo/": {Host: "bitbucket.org", Path: "workspace/project/repo"},
+	}
+	for input, expected := range tests {
+		remote, err := ParseGitURL(input)
+		assert.Nil(t, err, input)
+		assert.Equal(t, expected, remote, input)
+	}
+
+	for _, input := range []string{"repo", "https://github.com/", "git@host:../etc/passwd"} {
+		_, err := ParseGitURL(input)
+		assert.NotNil(t, err, input)
+	}
+}
+
+func TestGitLabHost(t *testing.T) {
+	config := GitLabConfig{URL: "https://gitlab.example.com:8443"}
+	assert.Equal(t, "gitlab.example.com", config.GetHost())
+
+	remote, err := ParseGitURL("https://gitlab.example.com:8443/platform/api.git")
+	assert.Nil(t, err)
+	assert.Equal(t, config.GetHost(), remote.Host, "listed and cloned repos have the same clone path")
+}
+
+func TestClonePath(t *testing.T) {
+	remote := GitRemote{Host: "gitlab.com", Path: "group/sub/repo"}
+
+	t.Run("should default to the clone dir, host and path", func(t *testing.T) {
+		path, err := CloneConfig{}.ClonePath(remote, "")
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.FromSlash("~/git/gitlab.com/group/sub/repo"), path, "~ is expanded by the callers")
+
+		path, err = CloneConfig{}.ClonePath(remote, "/src/")
+		assert.Nil(t, err)
+		assert.Equal(t, "/src/gitlab.com/group/sub/repo", path)
+	})
+
+	t.Run("should prefer the template of the host", func(t *testing.T) {
+		config := CloneConfig{
+			PathTemplate: "~/src/{{.Host}}/{{.Path}}",
+			Hosts:        map[string]string{"gitlab.com": "/work/{{.Owner}}-{{.Repo}}"},
+		}
+		path, err := config.ClonePath(remote, "")
+		assert.Nil(t, err)
+		assert.Equal(t, "/work/group/sub-repo", path)
+
+		path, err = config.ClonePath(GitRemote{Host: "github.com", Path: "acme/api"}, "")
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.FromSlash("~/src/github.com/acme/api"), path)
+	})
+
+	t.Run("should report unknown fields", func(t *testing.T) {
+		_, err := CloneConfig{PathTemplate: "{{.Namespace}}"}.ClonePath(remote, "")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestExpandAlias(t *testing.T) {
+	config := CloneConfig{Aliases: map[string]string{
+		"gl":   "git@gitlab.com:{{.Path}}.git",
+		"work": "ssh://git@git.corp:7999/{{.Path}}.git",
+	}}
+
+	repoURL, isAlias, err := config.ExpandAlias("gl:group/sub/repo")
+	assert.Nil(t, err)
+	assert.True(t, isAlias)
+	assert.Equal(t, "git@gitlab.com:group/sub/repo.git", repoURL)
+
+	repoURL, isAlias, err = config.ExpandAlias("work:team/service.git")
+	assert.Nil(t, err)
+	assert.True(t, isAlias)
+	assert.Equal(t, "ssh://git@git.corp:7999/team/service.git", repoURL)
+
+	for _, input := range []string{"joshmedeski/sesh", "git@gitlab.com:group/repo.git", "https://gl.example.com/group/repo"} {
+		repoURL, isAlias, err = config.ExpandAlias(input)
+		assert.Nil(t, err, input)
+		assert.False(t, isAlias, input)
+		assert.Equal(t, input, repoURL)
+	}
+
+	_, _, err = config.ExpandAlias("gl:")
+	assert.NotNil(t, err)
+}
+
+func TestSameRemote(t *testing.T) {
+	assert.True(t, SameRemote("git@github.com:joshmedeski/sesh.git", "https://github.com/JoshMedeski/sesh"))
+	assert.True(t, SameRemote("ssh://git@git.corp:7999/team/service.git", "git@git.corp:team/service"))
+	assert.False(t, SameRemote("git@github.com:joshmedeski/sesh.git", "git@gitlab.com:joshmedeski/sesh.git"))
+	assert.False(t, SameRemote("git@github.com:joshmedeski/sesh.git", "/tmp/sesh"))
+	assert.True(t, SameRemote("file:///srv/git/sesh.git", "file:///srv/git/sesh"))
+	assert.False(t, SameRemote("/srv/git/sesh.git", "/srv/git/other.git"))
+}
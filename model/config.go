@@ -1,15 +1,28 @@
 package model
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
 type (
 	Config struct {
-		StrictMode           bool                 `toml:"strict_mode"`
-		ImportPaths          []string             `toml:"import"`
-		DefaultSessionConfig DefaultSessionConfig `toml:"default_session"`
-		Blacklist            []string             `toml:"blacklist"`
-		SessionConfigs       []SessionConfig      `toml:"session"`
-		SortOrder            []string             `toml:"sort_order"`
-		WindowConfigs        []WindowConfig       `toml:"window"`
-		GitHub               GitHubConfig         `toml:"github"`
+		StrictMode           bool                 `toml:"strict_mode" description:"Fail on unknown fields in the config and its imports"`
+		ImportPaths          []string             `toml:"import" description:"Config files to import, supports ~ and glob patterns"`
+		DefaultSessionConfig DefaultSessionConfig `toml:"default_session" description:"Settings for sessions that aren't defined in the config"`
+		Blacklist            []string             `toml:"blacklist" description:"Regular expressions of session names to hide"`
+		SessionConfigs       []SessionConfig      `toml:"session" description:"Predefined sessions"`
+		SortOrder            []string             `toml:"sort_order" description:"Order of the sources when listing sessions (tmux, config, tmuxinator, zoxide, github, gitlab)"`
+		WindowConfigs        []WindowConfig       `toml:"window" description:"Windows that sessions can reference by name"`
+		GitHub               GitHubConfig         `toml:"github" description:"GitHub repositories as a session source"`
+		GitLab               GitLabConfig         `toml:"gitlab" description:"GitLab projects as a session source"`
+		Status               StatusConfig         `toml:"status" description:"Output of sesh status for the tmux status line"`
+		LocalConfig          LocalConfigSettings  `toml:"local_config" description:"Repo-local .sesh.toml files"`
+		Rules                []RuleConfig         `toml:"rule" description:"Profiles for sessions that aren't defined in the config, the first matching rule wins"`
+		Cache                CacheConfig          `toml:"cache" description:"Caching of listings and previews under ~/.cache/sesh"`
+		Clone                CloneConfig          `toml:"clone" description:"Defaults of sesh clone and of repositories cloned on connect"`
+		PostClone            []PostCloneHook      `toml:"post_clone" description:"Commands that bootstrap repositories once they're cloned, every matching hook runs once per repository"`
 	}
 	Evaluation struct {
 		StrictMode bool `toml:"strict_mode"`
@@ -18,23 +31,104 @@ type (
 	DefaultSessionConfig struct {
 		// TODO: mention breaking change in v2 release notes
 		// StartupScript  string `toml:"startup_script"`
-		StartupCommand string   `toml:"startup_command"`
-		Tmuxp          string   `toml:"tmuxp"`
-		Tmuxinator     string   `toml:"tmuxinator"`
-		PreviewCommand string   `toml:"preview_command"`
-		Windows        []string `toml:"windows"`
+		StartupCommand StartupCommand `toml:"startup_command" description:"Command to run when the session is created, {} is replaced with the session path"`
+		Tmuxp          string         `toml:"tmuxp" description:"Name of the tmuxp config to start the session with"`
+		Tmuxinator     string         `toml:"tmuxinator" description:"Name of the tmuxinator config to start the session with"`
+		PreviewCommand string         `toml:"preview_command" description:"Command to preview the session, {} is replaced with the session path"`
+		Windows        []string       `toml:"windows" description:"Names of the windows to create in the session"`
 	}
 
 	SessionConfig struct {
-		Name                string `toml:"name"`
-		Path                string `toml:"path"`
-		DisableStartCommand bool   `toml:"disable_startup_command"`
+		Name                string `toml:"name" description:"Name of the session"`
+		Path                string `toml:"path" description:"Directory of the session, supports ~"`
+		DisableStartCommand bool   `toml:"disable_startup_command" description:"Don't run the default startup command"`
 		DefaultSessionConfig
 	}
 
+	StatusConfig struct {
+		Format   string `toml:"format" description:"Go template used to render the status line"`
+		CacheTTL int    `toml:"cache_ttl" description:"Seconds to cache the status line for" default:"5"`
+	}
+
+	CacheConfig struct {
+		PreviewTTL int `toml:"preview_ttl" description:"Seconds to cache directory previews for, they're also refreshed when the directory changes, 0 disables it" default:"0"`
+	}
+
+	CloneConfig struct {
+		Depth             int               `toml:"depth" description:"Create shallow clones with this many commits, 0 clones the whole history" default:"0"`
+		RecurseSubmodules bool              `toml:"recurse_submodules" description:"Clone the submodules too"`
+		Filter            string            `toml:"filter" description:"Partial clone filter, e.g. blob:none"`
+		BareWorktree      bool              `toml:"bare_worktree" description:"Let sesh clone create <repo>/.bare with the default branch checked out in a worktree next to it"`
+		PathTemplate      string            `toml:"path_template" description:"Go template of clone paths with .Host, .Path, .Owner, .Repo and .CloneDir, supports ~" default:"{{.CloneDir}}/{{.Host}}/{{.Path}}"`
+		Hosts             map[string]string `toml:"hosts" description:"Clone path templates of specific hosts, e.g. \"gitlab.com\" = \"~/gl/{{.Path}}\""`
+		Aliases           map[string]string `toml:"aliases" description:"Url templates of shorthands like gl:group/repo, e.g. gl = \"git@gitlab.com:{{.Path}}.git\""`
+	}
+
+	LocalConfigSettings struct {
+		Enabled      bool     `toml:"enabled" description:"Load .sesh.toml files from session directories"`
+		TrustedPaths []string `toml:"trusted_paths" description:"Glob patterns of directories whose .sesh.toml is trusted without a prompt"`
+	}
+
+	// LocalConfig is a repo-local .sesh.toml file
+	LocalConfig struct {
+		StartupCommand StartupCommand `toml:"startup_command" description:"Command to run when the session is created"`
+		Windows        []string       `toml:"windows" description:"Names of the windows to create in the session"`
+		WindowConfigs  []WindowConfig `toml:"window" description:"Windows that the session can reference by name"`
+	}
+
+	// RuleConfig applies a profile to sessions that match all of its conditions
+	RuleConfig struct {
+		Name           string            `toml:"name" description:"Name of the rule"`
+		Path           string            `toml:"path" description:"Glob pattern of the session path, supports ~ and a trailing /** for subdirectories"`
+		Remote         string            `toml:"remote" description:"Regular expression of the origin remote url"`
+		Src            []string          `toml:"src" description:"Sources of the session (tmux, zoxide, dir, github, ...)"`
+		Markers        []string          `toml:"markers" description:"Files of which at least one must exist in the session path (go.mod, package.json, ...)"`
+		StartupCommand StartupCommand    `toml:"startup_command" description:"Command to run when the session is created"`
+		PreviewCommand string            `toml:"preview_command" description:"Command to preview the session"`
+		Windows        []string          `toml:"windows" description:"Names of the windows to create in the session"`
+		Env            map[string]string `toml:"env" description:"Environment variables to set on the tmux session"`
+	}
+
+	// PostCloneHook bootstraps a cloned repository that matches all of its
+	// conditions
+	PostCloneHook struct {
+		Name     string   `toml:"name" description:"Name of the hook, it's recorded in the repository once the hook ran"`
+		Host     string   `toml:"host" description:"Glob pattern of the host of the origin remote, e.g. git.corp or *.example.com"`
+		Path     string   `toml:"path" description:"Glob pattern of the repository path, supports ~ and a trailing /** for subdirectories"`
+		Commands []string `toml:"commands" description:"Commands to run in the repository, in order"`
+	}
+
+	// StartupStep is a command typed into a new session, optionally once the
+	// session is ready for it
+	StartupStep struct {
+		Run     string `toml:"run" json:"run" description:"Command to run"`
+		WaitFor string `toml:"wait_for,omitempty" json:"wait_for,omitempty" description:"Regular expression to wait for in the pane output before running the command"`
+		Delay   string `toml:"delay,omitempty" json:"delay,omitempty" description:"Time to wait before running the command, e.g. 500ms"`
+		Timeout string `toml:"timeout,omitempty" json:"timeout,omitempty" description:"How long to wait for wait_for before giving up" default:"10s"`
+	}
+
 	WindowConfig struct {
-		Name          string `toml:"name"`
-		StartupScript string `toml:"startup_script"`
-		Path          string `toml:"path"`
+		Name          string `toml:"name" description:"Name of the window"`
+		StartupScript string `toml:"startup_script" description:"Command to run in the window"`
+		Path          string `toml:"path" description:"Directory of the window, defaults to the session path"`
 	}
 )
+
+// Flags are the git clone flags of the config
+func (c CloneConfig) Flags() GitCloneFlags {
+	return GitCloneFlags{
+		Depth:             c.Depth,
+		RecurseSubmodules: c.RecurseSubmodules,
+		Filter:            c.Filter,
+	}
+}
+
+// ID identifies the hook in the repositories it ran in, its name or else a
+// hash of its commands
+func (h PostCloneHook) ID() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	sum := sha256.Sum256([]byte(strings.Join(h.Commands, "\n")))
+	return hex.EncodeToString(sum[:4])
+}
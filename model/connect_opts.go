@@ -4,4 +4,6 @@ type ConnectOpts struct {
 	Command    string
 	Switch     bool
 	Tmuxinator bool
+	// NoHooks skips the post_clone hooks of repositories cloned on connect
+	NoHooks bool
 }
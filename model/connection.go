@@ -7,4 +7,5 @@ type Connection struct {
 	Switch      bool // Whether to switch to the session (otherwise attach)
 	Found       bool // Whether the connection was found
 	New         bool // Whether the session was new
+	Bootstrap   bool // Whether to run the post_clone hooks in the session path
 }
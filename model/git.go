@@ -4,4 +4,31 @@ type GitCloneOptions struct {
 	Dir    string
 	CmdDir string
 	Repo   string
+	// BareWorktree clones into <dir>/.bare and checks the branch out in a
+	// worktree next to it
+	BareWorktree bool
+	// Fetch updates an existing checkout of the repo instead of cloning it
+	Fetch bool
+	// NoHooks skips the post_clone hooks
+	NoHooks bool
+	GitCloneFlags
+}
+
+// GitCloneFlags are passed on to git clone
+type GitCloneFlags struct {
+	Depth             int
+	Branch            string
+	RecurseSubmodules bool
+	Filter            string
+	// Quiet clones without showing progress
+	Quiet bool
+}
+
+// CloneResult is the outcome of cloning one of several repositories
+type CloneResult struct {
+	Repo string
+	Path string
+	// Skipped is set when the repository was already cloned
+	Skipped bool
+	Err     error
 }
@@ -1,60 +1,121 @@
 package model
 
 import (
-	"os"
+	"fmt"
+	"net/url"
 	"time"
 )
 
 type GitHubRepo struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	FullName    string `json:"full_name"`
-	Description string `json:"description"`
-	CloneURL    string `json:"clone_url"`
-	SSHURL      string `json:"ssh_url"`
-	HTMLURL     string `json:"html_url"`
-	Private     bool   `json:"private"`
-	Fork        bool   `json:"fork"`
-	Archived    bool   `json:"archived"`
-	Disabled    bool   `json:"disabled"`
-	Language    string `json:"language"`
-	UpdatedAt   string `json:"updated_at"`
-	PushedAt    string `json:"pushed_at"`
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	FullName    string   `json:"full_name"`
+	Description string   `json:"description"`
+	CloneURL    string   `json:"clone_url"`
+	SSHURL      string   `json:"ssh_url"`
+	HTMLURL     string   `json:"html_url"`
+	Private     bool     `json:"private"`
+	Fork        bool     `json:"fork"`
+	Archived    bool     `json:"archived"`
+	Disabled    bool     `json:"disabled"`
+	Language    string   `json:"language"`
+	UpdatedAt   string   `json:"updated_at"`
+	PushedAt    string   `json:"pushed_at"`
 	Topics      []string `json:"topics"`
 }
 
 type GitHubConfig struct {
 	// Deprecated: Use Organizations instead
-	Organization      string             `toml:"organization"`
-	Organizations     []GitHubOrgConfig  `toml:"organizations"`
-	Token             string             `toml:"token"`
-	CacheTimeout      int                `toml:"cache_timeout"` // in minutes
-	CloneDir          string             `toml:"clone_dir"`
-	UseSSH            bool               `toml:"use_ssh"`
-	IncludePersonal   bool               `toml:"include_personal"` // whether to include personal repos in addition to orgs
-	ShowUncloned      *bool              `toml:"show_uncloned"`    // whether to show uncloned repos when using --github flag (default: true)
-	ShowDescription   *bool              `toml:"show_description"` // whether to show repository descriptions (default: true)
+	Organization        string            `toml:"organization" description:"Deprecated: use organizations instead"`
+	Organizations       []GitHubOrgConfig `toml:"organizations" description:"Organizations (or users) to list repositories from"`
+	Token               string            `toml:"token" description:"GitHub token, defaults to $GITHUB_TOKEN or gh auth token"`
+	TokenCommand        string            `toml:"token_command" description:"Command that prints the GitHub token, e.g. pass show gh/work"`
+	TokenFile           string            `toml:"token_file" description:"File that contains the GitHub token, supports ~"`
+	CacheTimeout        int               `toml:"cache_timeout" description:"Minutes to cache repository lists for" default:"30"`
+	CloneDir            string            `toml:"clone_dir" description:"Directory to clone repositories into" default:"~/git"`
+	UseSSH              bool              `toml:"use_ssh" description:"Clone over SSH instead of HTTPS"`
+	IncludePersonal     bool              `toml:"include_personal" description:"Include the repositories of the authenticated user"`
+	IncludeStarred      bool              `toml:"include_starred" description:"Include the repositories starred by the authenticated user"`
+	Teams               []string          `toml:"teams" description:"Teams to list repositories from, e.g. my-org/my-team"`
+	IncludeCollaborator bool              `toml:"include_collaborator" description:"Include the repositories the authenticated user is a collaborator on"`
+	GraphQL             bool              `toml:"graphql" description:"Fetch organizations and personal repositories with batched GraphQL queries, concurrently per token"`
+	ShowUncloned        *bool             `toml:"show_uncloned" description:"Show repositories that aren't cloned yet" default:"true"`
+	ShowDescription     *bool             `toml:"show_description" description:"Show repository descriptions" default:"true"`
+	GitHubRepoFilter
 }
 
 type GitHubOrgConfig struct {
-	Name        string `toml:"name"`
-	DisplayName string `toml:"display_name"` // Optional: how to display this org in the list
-	Token       string `toml:"token"`        // Optional: org-specific token
+	Name         string `toml:"name" description:"Name of the organization or user"`
+	DisplayName  string `toml:"display_name" description:"How to display the organization in the list"`
+	Token        string `toml:"token" description:"Token for this organization, overrides the global token"`
+	TokenCommand string `toml:"token_command" description:"Command that prints the token for this organization"`
+	TokenFile    string `toml:"token_file" description:"File that contains the token for this organization, supports ~"`
+	Host         string `toml:"host" description:"Git host of a GitHub Enterprise Server, e.g. github.example.com" default:"github.com"`
+	BaseURL      string `toml:"base_url" description:"API url of a GitHub Enterprise Server, defaults to https://<host>/api/v3/"`
+	GitHubRepoFilter
 }
 
 type GitHubCache struct {
 	Repos     []GitHubRepo `json:"repos"`
 	CachedAt  time.Time    `json:"cached_at"`
 	ExpiresAt time.Time    `json:"expires_at"`
+	CacheValidators
+}
+
+// CacheValidators identify a response so it can be requested again
+// conditionally, an unchanged response is a 304 Not Modified
+type CacheValidators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// GitHubRateLimit is the quota of a token on a GitHub host as of the last
+// response
+type GitHubRateLimit struct {
+	Host string `json:"host"`
+	// Token identifies the token without revealing it, empty when unauthenticated
+	Token     string    `json:"token,omitempty"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+	// RetryAfter is set by a secondary rate limit, which GitHub applies to
+	// bursts of requests regardless of the remaining quota
+	RetryAfter time.Time `json:"retry_after,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Limited reports whether requests would be rejected until a later time,
+// which is returned
+func (l GitHubRateLimit) Limited(now time.Time) (time.Time, bool) {
+	if now.Before(l.RetryAfter) {
+		return l.RetryAfter, true
+	}
+	if l.Limit > 0 && l.Remaining == 0 && now.Before(l.Reset) {
+		return l.Reset, true
+	}
+	return time.Time{}, false
+}
+
+// Cache keys of the authenticated user's repositories, they start with @
+// which GitHub names can't
+const (
+	PersonalCacheKey     = "@me"
+	StarredCacheKey      = "@starred"
+	CollaboratorCacheKey = "@collaborator"
+)
+
+// TeamCacheKey is the cache key of the repositories of a team like org/team-slug
+func TeamCacheKey(team string) string {
+	return "@team/" + team
 }
 
 // GetOrganizations returns all configured organizations, including legacy single org config
 func (c GitHubConfig) GetOrganizations() []GitHubOrgConfig {
 	var orgs []GitHubOrgConfig
-	
+
 	// Add organizations from new config format
 	orgs = append(orgs, c.Organizations...)
-	
+
 	// Add legacy single organization if specified and not already in organizations list
 	if c.Organization != "" {
 		found := false
@@ -66,33 +127,68 @@ func (c GitHubConfig) GetOrganizations() []GitHubOrgConfig {
 		}
 		if !found {
 			legacyOrg := GitHubOrgConfig{
-				Name:        c.Organization,
-				DisplayName: c.Organization,
-				Token:       c.Token, // Use global token for legacy org
+				Name:         c.Organization,
+				DisplayName:  c.Organization,
+				Token:        c.Token, // Use global token for legacy org
+				TokenCommand: c.TokenCommand,
+				TokenFile:    c.TokenFile,
 			}
 			orgs = append(orgs, legacyOrg)
 		}
 	}
-	
+
 	return orgs
 }
 
-// GetTokenForOrg returns the appropriate token for an organization
-func (c GitHubConfig) GetTokenForOrg(orgName string) string {
-	// Check for org-specific token first
-	for _, org := range c.Organizations {
-		if org.Name == orgName && org.Token != "" {
-			return org.Token
+// HasSources reports whether any organization or other source is configured
+func (c GitHubConfig) HasSources() bool {
+	return len(c.GetOrganizations()) > 0 || c.IncludePersonal || c.IncludeStarred || len(c.Teams) > 0 || c.IncludeCollaborator
+}
+
+// DefaultGitHubHost is the host of organizations without a host or base_url
+const DefaultGitHubHost = "github.com"
+
+// GetHost returns the git host of the organization
+func (o GitHubOrgConfig) GetHost() string {
+	if o.Host != "" {
+		return o.Host
+	}
+	if o.BaseURL != "" {
+		if u, err := url.Parse(o.BaseURL); err == nil && u.Hostname() != "" {
+			return u.Hostname()
 		}
 	}
-	
-	// Fall back to global token if available
-	if c.Token != "" {
-		return c.Token
+	return DefaultGitHubHost
+}
+
+// GetBaseURL returns the API url of a GitHub Enterprise Server, or an empty
+// string for github.com
+func (o GitHubOrgConfig) GetBaseURL() string {
+	if o.BaseURL != "" {
+		return o.BaseURL
+	}
+	if host := o.GetHost(); host != DefaultGitHubHost {
+		return fmt.Sprintf("https://%s/api/v3/", host)
 	}
-	
-	// Fall back to GITHUB_TOKEN environment variable
-	return os.Getenv("GITHUB_TOKEN")
+	return ""
+}
+
+// CacheKey identifies the organization, including its host when it isn't github.com
+func (o GitHubOrgConfig) CacheKey() string {
+	if host := o.GetHost(); host != DefaultGitHubHost {
+		return host + "/" + o.Name
+	}
+	return o.Name
+}
+
+// GetOrgConfig returns the configured organization with the given name
+func (c GitHubConfig) GetOrgConfig(name string) (GitHubOrgConfig, bool) {
+	for _, org := range c.GetOrganizations() {
+		if org.Name == name {
+			return org, true
+		}
+	}
+	return GitHubOrgConfig{}, false
 }
 
 // ShouldShowDescription returns whether to show repository descriptions
@@ -116,5 +212,3 @@ func (c GitHubConfig) ShouldShowUncloned() bool {
 	}
 	return *c.ShowUncloned
 }
-
-
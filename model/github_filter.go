@@ -0,0 +1,159 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitHubRepoFilter selects the repositories to list, it's set under [github]
+// and can be overridden per organization
+type GitHubRepoFilter struct {
+	IncludeForks   *bool    `toml:"include_forks" description:"Include forked repositories" default:"true"`
+	Languages      []string `toml:"languages" description:"Only include repositories with one of these primary languages"`
+	Topics         []string `toml:"topics" description:"Only include repositories with at least one of these topics"`
+	ExcludeTopics  []string `toml:"exclude_topics" description:"Exclude repositories with any of these topics"`
+	NamePattern    string   `toml:"name_pattern" description:"Regular expression repository names must match"`
+	ExcludePattern string   `toml:"exclude_pattern" description:"Regular expression of repository names to exclude"`
+	Visibility     string   `toml:"visibility" description:"Only include public or private repositories" default:"all"`
+	PushedWithin   string   `toml:"pushed_within" description:"Only include repositories pushed within a duration, e.g. 180d, 2w or 12h"`
+}
+
+// GitHubVisibilities are the valid values of visibility
+var GitHubVisibilities = []string{"all", "public", "private"}
+
+// Override returns the filter with every field set in override replaced
+func (f GitHubRepoFilter) Override(override GitHubRepoFilter) GitHubRepoFilter {
+	if override.IncludeForks != nil {
+		f.IncludeForks = override.IncludeForks
+	}
+	if override.Languages != nil {
+		f.Languages = override.Languages
+	}
+	if override.Topics != nil {
+		f.Topics = override.Topics
+	}
+	if override.ExcludeTopics != nil {
+		f.ExcludeTopics = override.ExcludeTopics
+	}
+	if override.NamePattern != "" {
+		f.NamePattern = override.NamePattern
+	}
+	if override.ExcludePattern != "" {
+		f.ExcludePattern = override.ExcludePattern
+	}
+	if override.Visibility != "" {
+		f.Visibility = override.Visibility
+	}
+	if override.PushedWithin != "" {
+		f.PushedWithin = override.PushedWithin
+	}
+	return f
+}
+
+// GitHubRepoMatcher is a compiled GitHubRepoFilter
+type GitHubRepoMatcher struct {
+	filter      GitHubRepoFilter
+	name        *regexp.Regexp
+	exclude     *regexp.Regexp
+	pushedAfter time.Time
+}
+
+// Matcher compiles the patterns of the filter, pushed_within is relative to now
+func (f GitHubRepoFilter) Matcher(now time.Time) (*GitHubRepoMatcher, error) {
+	m := &GitHubRepoMatcher{filter: f}
+	var err error
+	if f.NamePattern != "" {
+		if m.name, err = regexp.Compile(f.NamePattern); err != nil {
+			return nil, fmt.Errorf("invalid name_pattern %q: %w", f.NamePattern, err)
+		}
+	}
+	if f.ExcludePattern != "" {
+		if m.exclude, err = regexp.Compile(f.ExcludePattern); err != nil {
+			return nil, fmt.Errorf("invalid exclude_pattern %q: %w", f.ExcludePattern, err)
+		}
+	}
+	if f.Visibility != "" && !slices.Contains(GitHubVisibilities, f.Visibility) {
+		return nil, fmt.Errorf("invalid visibility %q, expected one of %s", f.Visibility, strings.Join(GitHubVisibilities, ", "))
+	}
+	if f.PushedWithin != "" {
+		within, err := ParseAge(f.PushedWithin)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pushed_within %q: %w", f.PushedWithin, err)
+		}
+		m.pushedAfter = now.Add(-within)
+	}
+	return m, nil
+}
+
+// Match reports whether the repository passes every condition of the filter
+func (m *GitHubRepoMatcher) Match(repo GitHubRepo) bool {
+	f := m.filter
+	if repo.Fork && f.IncludeForks != nil && !*f.IncludeForks {
+		return false
+	}
+	if len(f.Languages) > 0 && !slices.ContainsFunc(f.Languages, func(language string) bool {
+		return strings.EqualFold(language, repo.Language)
+	}) {
+		return false
+	}
+	if len(f.Topics) > 0 && !slices.ContainsFunc(repo.Topics, func(topic string) bool {
+		return slices.Contains(f.Topics, topic)
+	}) {
+		return false
+	}
+	if slices.ContainsFunc(repo.Topics, func(topic string) bool {
+		return slices.Contains(f.ExcludeTopics, topic)
+	}) {
+		return false
+	}
+	if m.name != nil && !m.name.MatchString(repo.Name) {
+		return false
+	}
+	if m.exclude != nil && m.exclude.MatchString(repo.Name) {
+		return false
+	}
+	switch f.Visibility {
+	case "public":
+		if repo.Private {
+			return false
+		}
+	case "private":
+		if !repo.Private {
+			return false
+		}
+	}
+	if !m.pushedAfter.IsZero() {
+		// Repositories without a push date are kept
+		if pushedAt, err := time.Parse(time.RFC3339, repo.PushedAt); err == nil && pushedAt.Before(m.pushedAfter) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseAge parses a duration that also accepts days (d) and weeks (w), like 180d
+func ParseAge(age string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(age, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, fmt.Errorf("expected e.g. 180d, 2w or 12h")
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	duration, err := time.ParseDuration(age)
+	if err != nil {
+		return 0, fmt.Errorf("expected e.g. 180d, 2w or 12h")
+	}
+	return duration, nil
+}
+
+// GetRepoFilter returns the global filter with the organization's overrides
+func (c GitHubConfig) GetRepoFilter(org GitHubOrgConfig) GitHubRepoFilter {
+	return c.GitHubRepoFilter.Override(org.GitHubRepoFilter)
+}
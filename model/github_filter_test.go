@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHubRepoFilter(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	no := false
+	repo := GitHubRepo{Name: "api", Language: "Go", Topics: []string{"backend"}, Private: true, PushedAt: "2024-06-01T00:00:00Z"}
+
+	tests := map[string]struct {
+		filter   GitHubRepoFilter
+		repo     GitHubRepo
+		expected bool
+	}{
+		"no filter":                 {GitHubRepoFilter{}, repo, true},
+		"exclude forks":             {GitHubRepoFilter{IncludeForks: &no}, GitHubRepo{Name: "fork", Fork: true}, false},
+		"language ignores case":     {GitHubRepoFilter{Languages: []string{"go"}}, repo, true},
+		"other language":            {GitHubRepoFilter{Languages: []string{"Rust"}}, repo, false},
+		"topic":                     {GitHubRepoFilter{Topics: []string{"backend", "web"}}, repo, true},
+		"missing topic":             {GitHubRepoFilter{Topics: []string{"web"}}, repo, false},
+		"excluded topic":            {GitHubRepoFilter{ExcludeTopics: []string{"backend"}}, repo, false},
+		"name pattern":              {GitHubRepoFilter{NamePattern: "^a"}, repo, true},
+		"exclude pattern":           {GitHubRepoFilter{ExcludePattern: "-mirror$"}, GitHubRepo{Name: "api-mirror"}, false},
+		"public only":               {GitHubRepoFilter{Visibility: "public"}, repo, false},
+		"pushed within":             {GitHubRepoFilter{PushedWithin: "180d"}, repo, true},
+		"pushed too long ago":       {GitHubRepoFilter{PushedWithin: "2w"}, repo, false},
+		"unknown push date is kept": {GitHubRepoFilter{PushedWithin: "12h"}, GitHubRepo{Name: "new"}, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			matcher, err := tt.filter.Matcher(now)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, matcher.Match(tt.repo))
+		})
+	}
+
+	t.Run("should override the global filter per organization", func(t *testing.T) {
+		config := GitHubConfig{GitHubRepoFilter: GitHubRepoFilter{Languages: []string{"Go"}, Visibility: "private"}}
+		org := GitHubOrgConfig{Name: "acme", GitHubRepoFilter: GitHubRepoFilter{Languages: []string{"Rust"}}}
+		assert.Equal(t, GitHubRepoFilter{Languages: []string{"Rust"}, Visibility: "private"}, config.GetRepoFilter(org))
+	})
+
+	t.Run("should report invalid filters", func(t *testing.T) {
+		_, err := GitHubRepoFilter{PushedWithin: "soon"}.Matcher(now)
+		assert.ErrorContains(t, err, "pushed_within")
+		_, err = GitHubRepoFilter{Visibility: "internal"}.Matcher(now)
+		assert.ErrorContains(t, err, "visibility")
+	})
+}
@@ -0,0 +1,11 @@
+package model
+
+type GitHubPullRequest struct {
+	Repo    string `json:"repo"` // org/repo
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Author  string `json:"author"`
+	HeadRef string `json:"head_ref"`
+	HTMLURL string `json:"html_url"`
+	Draft   bool   `json:"draft"`
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"net/url"
+)
+
+type GitLabProject struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	Description       string `json:"description"`
+	HTTPURLToRepo     string `json:"http_url_to_repo"`
+	SSHURLToRepo      string `json:"ssh_url_to_repo"`
+	WebURL            string `json:"web_url"`
+	Archived          bool   `json:"archived"`
+	LastActivityAt    string `json:"last_activity_at"`
+}
+
+type GitLabConfig struct {
+	URL              string   `toml:"url" description:"Url of the GitLab instance" default:"https://gitlab.com"`
+	Groups           []string `toml:"groups" description:"Groups to list projects from, e.g. my-group or my-group/sub-group"`
+	IncludeSubgroups *bool    `toml:"include_subgroups" description:"Include the projects of subgroups" default:"true"`
+	Token            string   `toml:"token" description:"GitLab token, defaults to $GITLAB_TOKEN"`
+	TokenCommand     string   `toml:"token_command" description:"Command that prints the GitLab token"`
+	TokenFile        string   `toml:"token_file" description:"File that contains the GitLab token, supports ~"`
+	CacheTimeout     int      `toml:"cache_timeout" description:"Minutes to cache project lists for" default:"30"`
+	CloneDir         string   `toml:"clone_dir" description:"Directory to clone projects into" default:"~/git"`
+	UseSSH           bool     `toml:"use_ssh" description:"Clone over SSH instead of HTTPS"`
+}
+
+// DefaultGitLabURL is the instance used when no url is configured
+const DefaultGitLabURL = "https://gitlab.com"
+
+// GetURL returns the url of the GitLab instance
+func (c GitLabConfig) GetURL() string {
+	if c.URL != "" {
+		return c.URL
+	}
+	return DefaultGitLabURL
+}
+
+// GetHost returns the host of the GitLab instance without a port, like the
+// host ParseGitURL returns for its clone urls
+func (c GitLabConfig) GetHost() string {
+	if u, err := url.Parse(c.GetURL()); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return "gitlab.com"
+}
+
+// ShouldIncludeSubgroups returns whether to list the projects of subgroups
+// Default is true
+func (c GitLabConfig) ShouldIncludeSubgroups() bool {
+	if c.IncludeSubgroups == nil {
+		return true
+	}
+	return *c.IncludeSubgroups
+}
+
+// GetCacheTimeout returns the cache timeout in minutes
+func (c GitLabConfig) GetCacheTimeout() int {
+	if c.CacheTimeout == 0 {
+		return 30 // Default to 30 minutes
+	}
+	return c.CacheTimeout
+}
@@ -16,7 +16,7 @@ type (
 		Name string // The display name
 		Path string // The absolute directory path
 
-		StartupCommand        string         // The command to run when the session is started
+		StartupCommand        StartupCommand // The command to run when the session is started
 		PreviewCommand        string         // The command to run when the session is previewed
 		DisableStartupCommand bool           // Ignore the default startup command if present
 		Tmuxinator            string         // Name of the tmuxinator config
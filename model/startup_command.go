@@ -0,0 +1,100 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+// StartupCommand is a single command or a sequence of steps, in TOML either
+// startup_command = "nvim" or startup_command = ["direnv allow", { run = "nvim", wait_for = "direnv: export" }]
+type StartupCommand []StartupStep
+
+// NewStartupCommand creates a startup command that runs a single command
+func NewStartupCommand(command string) StartupCommand {
+	if command == "" {
+		return nil
+	}
+	return StartupCommand{{Run: command}}
+}
+
+// String joins the commands of the steps, for display
+func (c StartupCommand) String() string {
+	commands := make([]string, 0, len(c))
+	for _, step := range c {
+		commands = append(commands, step.Run)
+	}
+	return strings.Join(commands, "; ")
+}
+
+// Waits reports whether a step waits for a delay or the pane output, such
+// commands run in the background so connecting isn't blocked
+func (c StartupCommand) Waits() bool {
+	for _, step := range c {
+		if step.WaitFor != "" || step.Delay != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalTOML decodes a string or an array of strings and inline tables, the
+// decoder needs EnableUnmarshalerInterface for it to be used
+func (c *StartupCommand) UnmarshalTOML(node *unstable.Node) error {
+	switch node.Kind {
+	case unstable.String:
+		*c = NewStartupCommand(string(node.Data))
+		return nil
+	case unstable.Array:
+		steps := StartupCommand{}
+		children := node.Children()
+		for children.Next() {
+			step, err := decodeStartupStep(children.Node())
+			if err != nil {
+				return err
+			}
+			steps = append(steps, step)
+		}
+		*c = steps
+		return nil
+	default:
+		return fmt.Errorf("startup_command must be a string or an array of steps, got %s", node.Kind)
+	}
+}
+
+func decodeStartupStep(node *unstable.Node) (StartupStep, error) {
+	switch node.Kind {
+	case unstable.String:
+		return StartupStep{Run: string(node.Data)}, nil
+	case unstable.InlineTable:
+		step := StartupStep{}
+		fields := map[string]*string{
+			"run":      &step.Run,
+			"wait_for": &step.WaitFor,
+			"delay":    &step.Delay,
+			"timeout":  &step.Timeout,
+		}
+		keyValues := node.Children()
+		for keyValues.Next() {
+			keyValue := keyValues.Node()
+			key := keyValue.Key()
+			key.Next()
+			name := string(key.Node().Data)
+			field, ok := fields[name]
+			if !ok {
+				return StartupStep{}, fmt.Errorf("unknown startup step field %q", name)
+			}
+			if keyValue.Value().Kind != unstable.String {
+				return StartupStep{}, fmt.Errorf("startup step field %q must be a string", name)
+			}
+			*field = string(keyValue.Value().Data)
+		}
+		if step.Run == "" {
+			return StartupStep{}, fmt.Errorf("startup step is missing run")
+		}
+		return step, nil
+	default:
+		return StartupStep{}, fmt.Errorf("startup step must be a string or an inline table, got %s", node.Kind)
+	}
+}
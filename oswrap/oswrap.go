@@ -10,6 +10,8 @@ type Os interface {
 	ReadFile(name string) ([]byte, error)
 	Getenv(key string) string
 	Stat(name string) (os.FileInfo, error)
+	MkdirAll(path string, perm os.FileMode) error
+	ReadDir(name string) ([]os.DirEntry, error)
 }
 
 type RealOs struct{}
@@ -37,3 +39,11 @@ func (o *RealOs) Getenv(key string) string {
 func (o *RealOs) Stat(name string) (os.FileInfo, error) {
 	return os.Stat(name)
 }
+
+func (o *RealOs) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (o *RealOs) ReadDir(name string) ([]os.DirEntry, error) {
+	return os.ReadDir(name)
+}
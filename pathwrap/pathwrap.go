@@ -3,6 +3,7 @@ package pathwrap
 import (
 	"path"
 	"path/filepath"
+	"strings"
 )
 
 type Path interface {
@@ -10,6 +11,7 @@ type Path interface {
 	Abs(path string) (string, error)
 	Base(path string) string
 	EvalSymlinks(path string) (string, error)
+	Glob(pattern string) ([]string, error)
 }
 
 type RealPath struct{}
@@ -33,3 +35,17 @@ func (p *RealPath) Base(path string) string {
 func (p *RealPath) EvalSymlinks(path string) (string, error) {
 	return filepath.EvalSymlinks(path)
 }
+
+func (p *RealPath) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+// MatchGlob reports whether the path matches the pattern, a pattern ending in
+// /** matches the directory and everything below it, other patterns are
+// matched with filepath.Match
+func MatchGlob(pattern string, path string) (bool, error) {
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return path == prefix || strings.HasPrefix(path, prefix+"/"), nil
+	}
+	return filepath.Match(pattern, path)
+}
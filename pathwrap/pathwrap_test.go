@@ -0,0 +1,32 @@
+package pathwrap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	t.Run("should match a directory and everything below it", func(t *testing.T) {
+		for _, path := range []string{"/home/josh/work", "/home/josh/work/api", "/home/josh/work/api/web"} {
+			matched, err := MatchGlob("/home/josh/work/**", path)
+			assert.Nil(t, err)
+			assert.True(t, matched, path)
+		}
+		matched, _ := MatchGlob("/home/josh/work/**", "/home/josh/workshop")
+		assert.False(t, matched)
+	})
+
+	t.Run("should match other patterns one level at a time", func(t *testing.T) {
+		matched, err := MatchGlob("/home/josh/work/*", "/home/josh/work/api")
+		assert.Nil(t, err)
+		assert.True(t, matched)
+		matched, _ = MatchGlob("/home/josh/work/*", "/home/josh/work/api/web")
+		assert.False(t, matched)
+	})
+
+	t.Run("should report invalid patterns", func(t *testing.T) {
+		_, err := MatchGlob("/home/josh/[work", "/home/josh/work")
+		assert.Error(t, err)
+	})
+}
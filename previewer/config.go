@@ -2,16 +2,18 @@ package previewer
 
 import (
 	"github.com/joshmedeski/sesh/v2/lister"
+	"github.com/joshmedeski/sesh/v2/replacer"
 	"github.com/joshmedeski/sesh/v2/shell"
 )
 
 type ConfigPreviewStrategy struct {
-	lister lister.Lister
-	shell  shell.Shell
+	lister   lister.Lister
+	shell    shell.Shell
+	replacer replacer.Replacer
 }
 
-func NewConfigStrategy(lister lister.Lister, shell shell.Shell) *ConfigPreviewStrategy {
-	return &ConfigPreviewStrategy{lister: lister, shell: shell}
+func NewConfigStrategy(lister lister.Lister, shell shell.Shell, replacer replacer.Replacer) *ConfigPreviewStrategy {
+	return &ConfigPreviewStrategy{lister: lister, shell: shell, replacer: replacer}
 }
 
 func (s *ConfigPreviewStrategy) Execute(name string) (string, error) {
@@ -24,10 +26,15 @@ func (s *ConfigPreviewStrategy) Execute(name string) (string, error) {
 		return "", nil
 	}
 
+	command, err := s.replacer.Render(session.PreviewCommand, session)
+	if err != nil {
+		return "", err
+	}
+
 	replacements := map[string]string{
 		"{}": session.Path,
 	}
-	cmdParts, err := s.shell.PrepareCmd(session.PreviewCommand, replacements)
+	cmdParts, err := s.shell.PrepareCmd(command, replacements)
 	if err != nil {
 		return "", err
 	}
@@ -1,19 +1,34 @@
 package previewer
 
 import (
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/cache"
 	"github.com/joshmedeski/sesh/v2/dir"
 	"github.com/joshmedeski/sesh/v2/home"
 	"github.com/joshmedeski/sesh/v2/ls"
 )
 
+// CacheSource is the source of directory previews in the sesh cache
+const CacheSource = "preview"
+
 type DirectoryPreviewStrategy struct {
-	home home.Home
-	dir  dir.Dir
-	ls   ls.Ls
+	home  home.Home
+	dir   dir.Dir
+	ls    ls.Ls
+	cache cache.Cache
+	ttl   time.Duration
 }
 
-func NewDirectoryStrategy(home home.Home, dir dir.Dir, ls ls.Ls) *DirectoryPreviewStrategy {
-	return &DirectoryPreviewStrategy{home: home, dir: dir, ls: ls}
+type cachedPreview struct {
+	Output  string    `json:"output"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+func NewDirectoryStrategy(home home.Home, dir dir.Dir, ls ls.Ls, cache cache.Cache, ttl time.Duration) *DirectoryPreviewStrategy {
+	return &DirectoryPreviewStrategy{home: home, dir: dir, ls: ls, cache: cache, ttl: ttl}
 }
 
 func (s *DirectoryPreviewStrategy) Execute(name string) (string, error) {
@@ -21,10 +36,27 @@ func (s *DirectoryPreviewStrategy) Execute(name string) (string, error) {
 	isDir, absPath := s.dir.Dir(path)
 
 	if isDir {
+		if s.ttl <= 0 {
+			return s.ls.ListDirectory(absPath)
+		}
+
+		// Previews are cached until the ttl passes or the directory changes
+		var modTime time.Time
+		if info, err := os.Stat(absPath); err == nil {
+			modTime = info.ModTime()
+		}
+		var cached cachedPreview
+		if entry, found := s.cache.Get(CacheSource, absPath, &cached); found && !entry.Expired() && cached.ModTime.Equal(modTime) {
+			return cached.Output, nil
+		}
+
 		output, err := s.ls.ListDirectory(absPath)
 		if err != nil {
 			return "", err
 		}
+		if err := s.cache.Set(CacheSource, absPath, cachedPreview{Output: output, ModTime: modTime}, s.ttl); err != nil {
+			slog.Warn("Failed to cache preview", "path", absPath, "error", err)
+		}
 
 		return output, nil
 	}
@@ -1,12 +1,17 @@
 package previewer
 
 import (
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/cache"
 	"github.com/joshmedeski/sesh/v2/dir"
 	"github.com/joshmedeski/sesh/v2/home"
 	"github.com/joshmedeski/sesh/v2/icon"
 	"github.com/joshmedeski/sesh/v2/lister"
 	"github.com/joshmedeski/sesh/v2/ls"
 	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/replacer"
+	"github.com/joshmedeski/sesh/v2/rules"
 	"github.com/joshmedeski/sesh/v2/shell"
 	"github.com/joshmedeski/sesh/v2/tmux"
 )
@@ -30,12 +35,16 @@ func NewPreviewer(
 	ls ls.Ls,
 	config model.Config,
 	shell shell.Shell,
+	replacer replacer.Replacer,
+	rules rules.Rules,
+	cache cache.Cache,
 ) Previewer {
 	strategies := []PreviewStrategy{
 		NewTmuxStrategy(lister, tmux),
-		NewConfigStrategy(lister, shell),
+		NewConfigStrategy(lister, shell, replacer),
 		NewDefaultConfigStrategy(lister, config, ls),
-		NewDirectoryStrategy(home, dir, ls),
+		NewRuleStrategy(home, dir, lister, rules, shell, replacer),
+		NewDirectoryStrategy(home, dir, ls, cache, time.Duration(config.Cache.PreviewTTL)*time.Second),
 	}
 
 	return &RealPreviewer{
@@ -3,15 +3,19 @@ package previewer
 import (
 	"testing"
 
+	"github.com/joshmedeski/sesh/v2/cache"
 	"github.com/joshmedeski/sesh/v2/dir"
 	"github.com/joshmedeski/sesh/v2/home"
 	"github.com/joshmedeski/sesh/v2/icon"
 	"github.com/joshmedeski/sesh/v2/lister"
 	"github.com/joshmedeski/sesh/v2/ls"
 	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/replacer"
+	"github.com/joshmedeski/sesh/v2/rules"
 	"github.com/joshmedeski/sesh/v2/shell"
 	"github.com/joshmedeski/sesh/v2/tmux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -23,15 +27,17 @@ const (
 
 type PreviewerTestSuite struct {
 	suite.Suite
-	mockLister  *lister.MockLister
-	mockTmux    *tmux.MockTmux
-	mockIcon    *icon.MockIcon
-	mockDir     *dir.MockDir
-	mockHome    *home.MockHome
-	mockLs      *ls.MockLs
-	mockShell   *shell.MockShell
-	mockConnfig *model.Config
-	previewer   Previewer
+	mockLister   *lister.MockLister
+	mockTmux     *tmux.MockTmux
+	mockIcon     *icon.MockIcon
+	mockDir      *dir.MockDir
+	mockHome     *home.MockHome
+	mockLs       *ls.MockLs
+	mockShell    *shell.MockShell
+	mockReplacer *replacer.MockReplacer
+	mockRules    *rules.MockRules
+	mockConnfig  *model.Config
+	previewer    Previewer
 }
 
 func (suite *PreviewerTestSuite) SetupTest() {
@@ -57,6 +63,8 @@ func (suite *PreviewerTestSuite) initializeMocks() {
 	suite.mockHome = new(home.MockHome)
 	suite.mockLs = new(ls.MockLs)
 	suite.mockShell = new(shell.MockShell)
+	suite.mockReplacer = new(replacer.MockReplacer)
+	suite.mockRules = new(rules.MockRules)
 }
 
 func (suite *PreviewerTestSuite) initializePreviewer() {
@@ -69,6 +77,9 @@ func (suite *PreviewerTestSuite) initializePreviewer() {
 		suite.mockLs,
 		model.Config{},
 		suite.mockShell,
+		suite.mockReplacer,
+		suite.mockRules,
+		new(cache.MockCache),
 	)
 }
 
@@ -169,6 +180,26 @@ func (suite *PreviewerTestSuite) TestPreview_DirectoryStrategy() {
 	assert.Equal(suite.T(), testCase.expectedOutput, output)
 }
 
+func (suite *PreviewerTestSuite) TestPreview_RuleStrategy() {
+	previewCommand := "glow {{.Root}}/README.md"
+	suite.mockIcon.On("RemoveIcon", " ~/Code/JSXQL").Return("~/Code/JSXQL")
+	suite.mockLister.On("FindTmuxSession", "~/Code/JSXQL").Return(model.SeshSession{}, false)
+	suite.mockLister.On("FindConfigSession", "~/Code/JSXQL").Return(model.SeshSession{}, false)
+	suite.mockHome.On("ExpandHome", "~/Code/JSXQL").Return(testCodePath, nil)
+	suite.mockDir.On("Dir", testCodePath).Return(true, testCodePath)
+	suite.mockLister.On("FindZoxideSession", "~/Code/JSXQL").Return(model.SeshSession{Src: "zoxide"}, true)
+	session := model.SeshSession{Src: "zoxide", Name: "~/Code/JSXQL", Path: testCodePath}
+	suite.mockRules.On("Match", session).Return(model.RuleConfig{Name: "docs", PreviewCommand: previewCommand}, true, nil)
+	suite.mockReplacer.On("Render", previewCommand, session).Return("glow "+testCodePath+"/README.md", nil)
+	suite.mockShell.On("PrepareCmd", "glow "+testCodePath+"/README.md", map[string]string{"{}": testCodePath}).Return([]string{"glow", testCodePath + "/README.md"}, nil)
+	suite.mockShell.On("Cmd", "glow", testCodePath+"/README.md").Return("# JSXQL", nil)
+
+	output, err := suite.previewer.Preview(" ~/Code/JSXQL")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "# JSXQL", output)
+}
+
 func (suite *PreviewerTestSuite) TestPreview_NoMatch() {
 	testCase := struct {
 		inputName   string
@@ -213,6 +244,7 @@ func (suite *PreviewerTestSuite) setupConfigMocks(inputName string, trimmedName
 		Path:           expectedPath,
 		PreviewCommand: previewCommand,
 	}, true)
+	suite.mockReplacer.On("Render", previewCommand, mock.Anything).Return(previewCommand, nil)
 	suite.mockShell.On("PrepareCmd", previewCommand, map[string]string{"{}": expectedPath}).Return(previewCommandParts, nil)
 	suite.mockShell.On("Cmd", "ls", "-la").Return(expectedOutput, nil)
 }
@@ -223,6 +255,8 @@ func (suite *PreviewerTestSuite) setupDirectoryMocks(inputName, trimmedName, exp
 	suite.mockLister.On("FindConfigSession", trimmedName).Return(model.SeshSession{}, false)
 	suite.mockHome.On("ExpandHome", trimmedName).Return(expectedPath, nil)
 	suite.mockDir.On("Dir", expectedPath).Return(true, expectedPath)
+	suite.mockLister.On("FindZoxideSession", trimmedName).Return(model.SeshSession{}, false)
+	suite.mockRules.On("Match", mock.Anything).Return(model.RuleConfig{}, false, nil)
 	suite.mockLs.On("ListDirectory", expectedPath).Return(expectedOutput, nil)
 }
 
@@ -0,0 +1,57 @@
+package previewer
+
+import (
+	"github.com/joshmedeski/sesh/v2/dir"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/lister"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/replacer"
+	"github.com/joshmedeski/sesh/v2/rules"
+	"github.com/joshmedeski/sesh/v2/shell"
+)
+
+type RulePreviewStrategy struct {
+	home     home.Home
+	dir      dir.Dir
+	lister   lister.Lister
+	rules    rules.Rules
+	shell    shell.Shell
+	replacer replacer.Replacer
+}
+
+func NewRuleStrategy(home home.Home, dir dir.Dir, lister lister.Lister, rules rules.Rules, shell shell.Shell, replacer replacer.Replacer) *RulePreviewStrategy {
+	return &RulePreviewStrategy{home: home, dir: dir, lister: lister, rules: rules, shell: shell, replacer: replacer}
+}
+
+func (s *RulePreviewStrategy) Execute(name string) (string, error) {
+	path, _ := s.home.ExpandHome(name)
+	isDir, absPath := s.dir.Dir(path)
+	if !isDir {
+		return "", nil
+	}
+
+	session := model.SeshSession{Src: "dir", Name: name, Path: absPath}
+	if zoxideSession, exists := s.lister.FindZoxideSession(name); exists {
+		session.Src = zoxideSession.Src
+	}
+
+	rule, exists, err := s.rules.Match(session)
+	if err != nil {
+		return "", err
+	}
+	if !exists || rule.PreviewCommand == "" {
+		return "", nil
+	}
+
+	command, err := s.replacer.Render(rule.PreviewCommand, session)
+	if err != nil {
+		return "", err
+	}
+
+	cmdParts, err := s.shell.PrepareCmd(command, map[string]string{"{}": session.Path})
+	if err != nil {
+		return "", err
+	}
+
+	return s.shell.Cmd(cmdParts[0], cmdParts[1:]...)
+}
@@ -0,0 +1,135 @@
+package pullrequest
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshmedeski/sesh/v2/configurator"
+	"github.com/joshmedeski/sesh/v2/connector"
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/github"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+)
+
+type PullRequest interface {
+	// Open checks out a pull request in its own worktree and connects to it
+	Open(ref string) (string, error)
+	// List lists the open pull requests of an org/repo
+	List(repo string) ([]model.GitHubPullRequest, error)
+}
+
+type RealPullRequest struct {
+	os        oswrap.Os
+	connector connector.Connector
+	git       git.Git
+	home      home.Home
+	client    github.Client
+	tokens    configurator.TokenResolver
+	config    model.Config
+	shorthand github.ShorthandConverter
+}
+
+func NewPullRequest(os oswrap.Os, connector connector.Connector, git git.Git, home home.Home, client github.Client, tokens configurator.TokenResolver, config model.Config) PullRequest {
+	return &RealPullRequest{
+		os:        os,
+		connector: connector,
+		git:       git,
+		home:      home,
+		client:    client,
+		tokens:    tokens,
+		config:    config,
+		shorthand: github.NewShorthandConverter(),
+	}
+}
+
+func (p *RealPullRequest) Open(ref string) (string, error) {
+	// Lines of `sesh pr --list` start with the pull request
+	ref, _, _ = strings.Cut(strings.TrimSpace(ref), " ")
+	org, repo, number, err := p.shorthand.ExtractPullRequest(ref)
+	if err != nil {
+		return "", err
+	}
+
+	clonePath, err := p.shorthand.GetClonePath(org, repo, p.config)
+	if err != nil {
+		return "", err
+	}
+	clonePath, err = p.home.ExpandHome(clonePath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := p.os.Stat(clonePath); os.IsNotExist(err) {
+		url, err := p.shorthand.ConvertToURL(org+"/"+repo, p.config.GitHub)
+		if err != nil {
+			return "", err
+		}
+		parentDir := filepath.Dir(clonePath)
+		if err := p.os.MkdirAll(parentDir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create directory %s: %w", parentDir, err)
+		}
+		if _, err := p.git.Clone(url, parentDir, filepath.Base(clonePath), p.config.Clone.Flags()); err != nil {
+			return "", fmt.Errorf("failed to clone %s/%s: %w", org, repo, err)
+		}
+	}
+
+	// The worktree is next to the clone, so the session is named like repo-pr-123
+	branch := fmt.Sprintf("pr-%d", number)
+	worktreePath := filepath.Join(filepath.Dir(clonePath), fmt.Sprintf("%s-%s", repo, branch))
+	refspec := fmt.Sprintf("pull/%d/head", number)
+	if _, err := p.os.Stat(worktreePath); os.IsNotExist(err) {
+		if _, err := p.git.Fetch(clonePath, "origin", refspec); err != nil {
+			return "", fmt.Errorf("failed to fetch pull request %d: %w", number, err)
+		}
+		if _, err := p.git.WorktreeAdd(clonePath, worktreePath, branch, "FETCH_HEAD"); err != nil {
+			return "", fmt.Errorf("failed to create worktree for pull request %d: %w", number, err)
+		}
+		return p.connector.Connect(worktreePath, model.ConnectOpts{})
+	}
+
+	warning, err := p.update(worktreePath, refspec, number)
+	if err != nil {
+		return "", err
+	}
+	out, err := p.connector.Connect(worktreePath, model.ConnectOpts{})
+	return strings.TrimSpace(strings.Join([]string{warning, out}, "\n")), err
+}
+
+// update moves an existing worktree to the latest commit of the pull request,
+// FETCH_HEAD belongs to the worktree it's fetched in. A worktree with local
+// changes is left alone and a warning is returned.
+func (p *RealPullRequest) update(worktreePath, refspec string, number int) (string, error) {
+	if _, err := p.git.Fetch(worktreePath, "origin", refspec); err != nil {
+		return "", fmt.Errorf("failed to fetch pull request %d: %w", number, err)
+	}
+	dirty, err := p.git.IsDirty(worktreePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to check the worktree of pull request %d: %w", number, err)
+	}
+	if dirty {
+		slog.Debug("Worktree of pull request has local changes", "path", worktreePath)
+		return fmt.Sprintf("warning: %s has local changes, it wasn't updated to the latest commit of pull request %d", worktreePath, number), nil
+	}
+	if _, err := p.git.ResetHard(worktreePath, "FETCH_HEAD"); err != nil {
+		return "", fmt.Errorf("failed to update the worktree of pull request %d: %w", number, err)
+	}
+	return "", nil
+}
+
+func (p *RealPullRequest) List(input string) ([]model.GitHubPullRequest, error) {
+	org, repo, err := p.shorthand.ExtractOrgAndRepo(input)
+	if err != nil {
+		return nil, err
+	}
+
+	token := p.tokens.GitHubToken(p.config.GitHub, org)
+	client := p.client
+	if orgConfig, ok := p.config.GitHub.GetOrgConfig(org); ok {
+		client = client.WithBaseURL(orgConfig.GetBaseURL())
+	}
+	return client.ListPullRequests(org, repo, token)
+}
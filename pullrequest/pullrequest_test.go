@@ -0,0 +1,112 @@
+package pullrequest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/configurator"
+	"github.com/joshmedeski/sesh/v2/connector"
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/github"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newMockHome expands ~ to /home/test
+func newMockHome() *home.MockHome {
+	mockHome := new(home.MockHome)
+	mockHome.On("ExpandHome", mock.Anything).Return(func(path string) (string, error) {
+		return strings.Replace(path, "~", "/home/test", 1), nil
+	})
+	return mockHome
+}
+
+func TestOpen(t *testing.T) {
+	cloneDir := "/home/test/git"
+	config := model.Config{GitHub: model.GitHubConfig{CloneDir: cloneDir}}
+	clonePath := filepath.Join(cloneDir, "github.com", "acme", "api")
+	worktreePath := filepath.Join(cloneDir, "github.com", "acme", "api-pr-123")
+
+	for name, ref := range map[string]string{
+		"shorthand":      "acme/api#123",
+		"url":            "https://github.com/acme/api/pull/123/files",
+		"list selection": "acme/api#123 Fix the thing (wile)",
+	} {
+		t.Run("should check out a pull request from a "+name, func(t *testing.T) {
+			mockOs := new(oswrap.MockOs)
+			mockGit := new(git.MockGit)
+			mockConnector := new(connector.MockConnector)
+			mockOs.On("Stat", mock.Anything).Return(nil, os.ErrNotExist)
+			mockOs.On("MkdirAll", filepath.Dir(clonePath), os.FileMode(0755)).Return(nil)
+			mockGit.On("Clone", "https://github.com/acme/api.git", filepath.Dir(clonePath), "api", model.GitCloneFlags{}).Return("", nil)
+			mockGit.On("Fetch", clonePath, "origin", "pull/123/head").Return("", nil)
+			mockGit.On("WorktreeAdd", clonePath, worktreePath, "pr-123", "FETCH_HEAD").Return("", nil)
+			mockConnector.On("Connect", worktreePath, model.ConnectOpts{}).Return("connected", nil)
+
+			out, err := NewPullRequest(mockOs, mockConnector, mockGit, newMockHome(), new(github.MockClient), new(configurator.MockTokenResolver), config).Open(ref)
+			assert.Nil(t, err)
+			assert.Equal(t, "connected", out)
+			mockGit.AssertExpectations(t)
+			mockOs.AssertExpectations(t)
+		})
+	}
+
+	existing := func() (*git.MockGit, *connector.MockConnector, PullRequest) {
+		mockOs := new(oswrap.MockOs)
+		mockGit := new(git.MockGit)
+		mockConnector := new(connector.MockConnector)
+		mockOs.On("Stat", mock.Anything).Return(nil, nil)
+		mockGit.On("Fetch", worktreePath, "origin", "pull/123/head").Return("", nil)
+		mockConnector.On("Connect", worktreePath, model.ConnectOpts{}).Return("connected", nil)
+		return mockGit, mockConnector, NewPullRequest(mockOs, mockConnector, mockGit, newMockHome(), new(github.MockClient), new(configurator.MockTokenResolver), config)
+	}
+
+	t.Run("should update a clean worktree that already exists", func(t *testing.T) {
+		mockGit, _, pr := existing()
+		mockGit.On("IsDirty", worktreePath).Return(false, nil)
+		mockGit.On("ResetHard", worktreePath, "FETCH_HEAD").Return("", nil)
+
+		out, err := pr.Open("acme/api#123")
+		assert.Nil(t, err)
+		assert.Equal(t, "connected", out)
+		mockGit.AssertExpectations(t)
+		mockGit.AssertNotCalled(t, "WorktreeAdd", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should warn instead of updating a worktree with changes", func(t *testing.T) {
+		mockGit, _, pr := existing()
+		mockGit.On("IsDirty", worktreePath).Return(true, nil)
+
+		out, err := pr.Open("acme/api#123")
+		assert.Nil(t, err)
+		assert.Equal(t, "warning: "+worktreePath+" has local changes, it wasn't updated to the latest commit of pull request 123\nconnected", out)
+		mockGit.AssertNotCalled(t, "ResetHard", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject other input", func(t *testing.T) {
+		_, err := NewPullRequest(new(oswrap.MockOs), new(connector.MockConnector), new(git.MockGit), newMockHome(), new(github.MockClient), new(configurator.MockTokenResolver), config).Open("acme/api")
+		assert.ErrorContains(t, err, "invalid pull request")
+	})
+}
+
+func TestList(t *testing.T) {
+	config := model.Config{GitHub: model.GitHubConfig{
+		Organizations: []model.GitHubOrgConfig{{Name: "acme", Host: "github.example.com", Token: "token"}},
+	}}
+	pullRequests := []model.GitHubPullRequest{{Repo: "acme/api", Number: 123, Title: "Fix the thing"}}
+
+	mockClient := new(github.MockClient)
+	mockClient.On("WithBaseURL", "https://github.example.com/api/v3/").Return(mockClient)
+	mockClient.On("ListPullRequests", "acme", "api", "token").Return(pullRequests, nil)
+	mockTokens := new(configurator.MockTokenResolver)
+	mockTokens.On("GitHubToken", config.GitHub, "acme").Return("token")
+
+	listed, err := NewPullRequest(new(oswrap.MockOs), new(connector.MockConnector), new(git.MockGit), newMockHome(), mockClient, mockTokens, config).List("acme/api")
+	assert.Nil(t, err)
+	assert.Equal(t, pullRequests, listed)
+}
@@ -0,0 +1,38 @@
+package replacer
+
+import "github.com/joshmedeski/sesh/v2/git"
+
+// Data is available as the dot in command templates, the git fields are
+// looked up only when a template uses them
+type Data struct {
+	Path string // The absolute directory path of the session
+	Name string // The name of the session
+	Src  string // The source of the session (config, tmux, zoxide, tmuxinator)
+	git  git.Git
+}
+
+// Root is the top level of the git repository, or the path outside of one
+func (d Data) Root() string {
+	if isGit, root, err := d.git.ShowTopLevel(d.Path); err == nil && isGit && root != "" {
+		return root
+	}
+	return d.Path
+}
+
+// GitBranch is the current branch, or empty outside of a git repository
+func (d Data) GitBranch() string {
+	branch, err := d.git.CurrentBranch(d.Path)
+	if err != nil {
+		return ""
+	}
+	return branch
+}
+
+// GitRemote is the url of the origin remote, or empty when there is none
+func (d Data) GitRemote() string {
+	remote, err := d.git.RemoteURL(d.Path)
+	if err != nil {
+		return ""
+	}
+	return remote
+}
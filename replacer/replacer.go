@@ -1,17 +1,30 @@
 package replacer
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
 	ahocorasick "github.com/petar-dambovaliev/aho-corasick"
 )
 
 type Replacer interface {
 	Replace(command string, replacements map[string]string) string
+	// Render fills in the template placeholders of a command for a session
+	Render(command string, session model.SeshSession) (string, error)
 }
 
-type RealReplacer struct{}
+type RealReplacer struct {
+	os  oswrap.Os
+	git git.Git
+}
 
-func NewReplacer() Replacer {
-	return &RealReplacer{}
+func NewReplacer(os oswrap.Os, git git.Git) Replacer {
+	return &RealReplacer{os, git}
 }
 
 func (r *RealReplacer) Replace(command string, replacements map[string]string) string {
@@ -26,6 +39,24 @@ func (r *RealReplacer) Replace(command string, replacements map[string]string) s
 	return replacer.ReplaceAll(command, replacementArray)
 }
 
+func (r *RealReplacer) Render(command string, session model.SeshSession) (string, error) {
+	if strings.Contains(command, "{{") {
+		tmpl, err := template.New("command").Funcs(template.FuncMap{"env": r.os.Getenv}).Parse(command)
+		if err != nil {
+			return "", fmt.Errorf("invalid template %q: %w", command, err)
+		}
+		var out bytes.Buffer
+		data := Data{Path: session.Path, Name: session.Name, Src: session.Src, git: r.git}
+		if err := tmpl.Execute(&out, data); err != nil {
+			return "", fmt.Errorf("couldn't render %q: %w", command, err)
+		}
+		command = out.String()
+	}
+
+	// {} predates the templates and is kept for backwards compatibility
+	return r.Replace(command, map[string]string{"{}": session.Path}), nil
+}
+
 func getAhoCorasick(dictionary []string) ahocorasick.AhoCorasick {
 	builder := ahocorasick.NewAhoCorasickBuilder(ahocorasick.Opts{
 		AsciiCaseInsensitive: true,
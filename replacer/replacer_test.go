@@ -1,7 +1,13 @@
 package replacer
 
 import (
+	"fmt"
 	"testing"
+
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/stretchr/testify/assert"
 )
 
 type testCase struct {
@@ -32,13 +38,60 @@ func TestReplace(t *testing.T) {
 
 	for name, test := range testCases {
 		t.Run(name, func(t *testing.T) {
-			replacer := NewReplacer()
+			replacer := NewReplacer(new(oswrap.MockOs), new(git.MockGit))
 			result := replacer.Replace(test.input, defaultReplacements)
 			if result != test.expected {
 				t.Errorf("expected %s, got %s", test.expected, result)
 			}
 		})
 	}
-	t.Run("", func(t *testing.T) {
+}
+
+func TestRender(t *testing.T) {
+	session := model.SeshSession{Src: "zoxide", Name: "sesh", Path: "/home/test/c/sesh/cmd"}
+	setup := func() (Replacer, *oswrap.MockOs, *git.MockGit) {
+		mockOs := new(oswrap.MockOs)
+		mockGit := new(git.MockGit)
+		return NewReplacer(mockOs, mockGit), mockOs, mockGit
+	}
+
+	t.Run("should keep replacing {}", func(t *testing.T) {
+		replacer, _, _ := setup()
+		result, err := replacer.Render("nvim {}", session)
+		assert.Nil(t, err)
+		assert.Equal(t, "nvim /home/test/c/sesh/cmd", result)
+	})
+
+	t.Run("should render session fields and env", func(t *testing.T) {
+		replacer, mockOs, _ := setup()
+		mockOs.On("Getenv", "EDITOR").Return("nvim")
+		result, err := replacer.Render(`{{env "EDITOR"}} {{.Path}} # {{.Name}} from {{.Src}}`, session)
+		assert.Nil(t, err)
+		assert.Equal(t, "nvim /home/test/c/sesh/cmd # sesh from zoxide", result)
+	})
+
+	t.Run("should render git fields", func(t *testing.T) {
+		replacer, _, mockGit := setup()
+		mockGit.On("ShowTopLevel", session.Path).Return(true, "/home/test/c/sesh", nil)
+		mockGit.On("CurrentBranch", session.Path).Return("main", nil)
+		mockGit.On("RemoteURL", session.Path).Return("git@github.com:joshmedeski/sesh.git", nil)
+		result, err := replacer.Render("cd {{.Root}} && echo {{.GitBranch}} {{.GitRemote}}", session)
+		assert.Nil(t, err)
+		assert.Equal(t, "cd /home/test/c/sesh && echo main git@github.com:joshmedeski/sesh.git", result)
+	})
+
+	t.Run("should fall back outside of a git repository", func(t *testing.T) {
+		replacer, _, mockGit := setup()
+		mockGit.On("ShowTopLevel", session.Path).Return(false, "", fmt.Errorf("not a git repository"))
+		mockGit.On("CurrentBranch", session.Path).Return("", fmt.Errorf("not a git repository"))
+		result, err := replacer.Render("{{.Root}}:{{.GitBranch}}", session)
+		assert.Nil(t, err)
+		assert.Equal(t, "/home/test/c/sesh/cmd:", result)
+	})
+
+	t.Run("should report invalid templates", func(t *testing.T) {
+		replacer, _, _ := setup()
+		_, err := replacer.Render("echo {{.Path", session)
+		assert.NotNil(t, err)
 	})
 }
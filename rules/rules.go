@@ -0,0 +1,127 @@
+package rules
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/joshmedeski/sesh/v2/pathwrap"
+)
+
+type Rules interface {
+	// Match returns the first rule that matches the session
+	Match(session model.SeshSession) (model.RuleConfig, bool, error)
+	// Explain evaluates every rule against the session
+	Explain(session model.SeshSession) ([]Explanation, error)
+}
+
+// Explanation is the outcome of a rule with the reason for each condition
+type Explanation struct {
+	Rule    model.RuleConfig
+	Matched bool
+	Reasons []string
+}
+
+type RealRules struct {
+	config model.Config
+	os     oswrap.Os
+	home   home.Home
+	git    git.Git
+}
+
+func NewRules(config model.Config, os oswrap.Os, home home.Home, git git.Git) Rules {
+	return &RealRules{config, os, home, git}
+}
+
+func (r *RealRules) Match(session model.SeshSession) (model.RuleConfig, bool, error) {
+	for _, rule := range r.config.Rules {
+		explanation, err := r.evaluate(rule, session)
+		if err != nil {
+			return model.RuleConfig{}, false, err
+		}
+		if explanation.Matched {
+			return rule, true, nil
+		}
+	}
+	return model.RuleConfig{}, false, nil
+}
+
+func (r *RealRules) Explain(session model.SeshSession) ([]Explanation, error) {
+	explanations := make([]Explanation, 0, len(r.config.Rules))
+	for _, rule := range r.config.Rules {
+		explanation, err := r.evaluate(rule, session)
+		if err != nil {
+			return nil, err
+		}
+		explanations = append(explanations, explanation)
+	}
+	return explanations, nil
+}
+
+// evaluate checks every condition of the rule, a rule matches when all of its
+// conditions do
+func (r *RealRules) evaluate(rule model.RuleConfig, session model.SeshSession) (Explanation, error) {
+	explanation := Explanation{Rule: rule, Matched: true}
+	check := func(matched bool, format string, args ...any) {
+		reason := fmt.Sprintf(format, args...)
+		if matched {
+			reason = "✓ " + reason
+		} else {
+			reason = "✗ " + reason
+			explanation.Matched = false
+		}
+		explanation.Reasons = append(explanation.Reasons, reason)
+	}
+
+	if rule.Path != "" {
+		matched, err := r.matchPath(rule.Path, session.Path)
+		if err != nil {
+			return Explanation{}, err
+		}
+		check(matched, "path %s matches %q", session.Path, rule.Path)
+	}
+
+	if rule.Remote != "" {
+		re, err := regexp.Compile(rule.Remote)
+		if err != nil {
+			return Explanation{}, fmt.Errorf("invalid remote pattern %q in rule %q: %w", rule.Remote, rule.Name, err)
+		}
+		remote, _ := r.git.RemoteURL(session.Path)
+		check(remote != "" && re.MatchString(remote), "remote %q matches %q", remote, rule.Remote)
+	}
+
+	if len(rule.Src) > 0 {
+		check(slices.Contains(rule.Src, session.Src), "source %q is one of %s", session.Src, strings.Join(rule.Src, ", "))
+	}
+
+	if len(rule.Markers) > 0 {
+		found := ""
+		for _, marker := range rule.Markers {
+			if _, err := r.os.Stat(filepath.Join(session.Path, marker)); err == nil {
+				found = marker
+				break
+			}
+		}
+		if found != "" {
+			check(true, "marker %s exists", found)
+		} else {
+			check(false, "none of %s exist", strings.Join(rule.Markers, ", "))
+		}
+	}
+
+	return explanation, nil
+}
+
+func (r *RealRules) matchPath(pattern string, path string) (bool, error) {
+	expanded, err := r.home.ExpandHome(pattern)
+	if err != nil {
+		return false, fmt.Errorf("couldn't expand home: %w", err)
+	}
+	return pathwrap.MatchGlob(expanded, path)
+}
@@ -0,0 +1,78 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMatch(t *testing.T) {
+	config := model.Config{Rules: []model.RuleConfig{
+		{Name: "work", Path: "~/work/**", Remote: `github\.com[:/]acme/`},
+		{Name: "go", Markers: []string{"go.mod"}, StartupCommand: model.NewStartupCommand("go test ./...")},
+		{Name: "github", Src: []string{"github"}},
+	}}
+	setup := func(files ...string) Rules {
+		mockOs := new(oswrap.MockOs)
+		mockHome := new(home.MockHome)
+		mockGit := new(git.MockGit)
+		mockHome.On("ExpandHome", "~/work/**").Return("/home/test/work/**", nil)
+		mockGit.On("RemoteURL", "/home/test/work/api").Return("git@github.com:acme/api.git", nil)
+		mockGit.On("RemoteURL", mock.Anything).Return("", fmt.Errorf("no remote"))
+		mockOs.On("Stat", mock.Anything).Return(func(name string) (os.FileInfo, error) {
+			for _, file := range files {
+				if file == name {
+					return nil, nil
+				}
+			}
+			return nil, os.ErrNotExist
+		})
+		return NewRules(config, mockOs, mockHome, mockGit)
+	}
+
+	t.Run("should match path and remote", func(t *testing.T) {
+		rule, found, err := setup().Match(model.SeshSession{Src: "zoxide", Path: "/home/test/work/api"})
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "work", rule.Name)
+	})
+
+	t.Run("should match marker files", func(t *testing.T) {
+		rule, found, err := setup("/home/test/c/sesh/go.mod").Match(model.SeshSession{Src: "zoxide", Path: "/home/test/c/sesh"})
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "go", rule.Name)
+	})
+
+	t.Run("should match the source", func(t *testing.T) {
+		rule, found, err := setup().Match(model.SeshSession{Src: "github", Path: "/home/test/git/github.com/acme/web"})
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "github", rule.Name)
+	})
+
+	t.Run("should not match when a condition fails", func(t *testing.T) {
+		_, found, err := setup().Match(model.SeshSession{Src: "zoxide", Path: "/home/test/work/notes"})
+		assert.Nil(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("should explain every rule", func(t *testing.T) {
+		explanations, err := setup().Explain(model.SeshSession{Src: "zoxide", Path: "/home/test/work/notes"})
+		assert.Nil(t, err)
+		assert.Len(t, explanations, 3)
+		assert.False(t, explanations[0].Matched)
+		assert.Equal(t, []string{
+			`✓ path /home/test/work/notes matches "~/work/**"`,
+			`✗ remote "" matches "github\\.com[:/]acme/"`,
+		}, explanations[0].Reasons)
+		assert.Equal(t, []string{"✗ none of go.mod exist"}, explanations[1].Reasons)
+	})
+}
@@ -1,16 +1,23 @@
 package seshcli
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
-	"path/filepath"
+	"slices"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/joshmedeski/sesh/v2/cache"
 	"github.com/joshmedeski/sesh/v2/github"
+	"github.com/joshmedeski/sesh/v2/lister"
+	"github.com/joshmedeski/sesh/v2/model"
 )
 
-func NewCacheCommand(githubCache github.Cache) *cobra.Command {
+func NewCacheCommand(config model.Config, cache cache.Cache, githubRateLimits github.RateLimits, githubLister lister.GitHub, lister lister.Lister) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "cache",
 		Short: "Manage sesh cache",
@@ -18,96 +25,173 @@ func NewCacheCommand(githubCache github.Cache) *cobra.Command {
 
 	// Add subcommands
 	cmd.AddCommand(
-		NewCacheClearCommand(githubCache),
-		NewCacheInfoCommand(githubCache),
+		NewCacheClearCommand(cache),
+		NewCacheInfoCommand(cache, githubRateLimits),
+		NewCacheRefreshCommand(config, githubLister),
+		NewCacheWarmCommand(lister),
 	)
 
 	return cmd
 }
 
-func NewCacheClearCommand(githubCache github.Cache) *cobra.Command {
+func NewCacheClearCommand(cache cache.Cache) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "clear",
+		Use:     "clear [source]",
 		Aliases: []string{"clean"},
-		Short:   "Clear GitHub cache",
+		Short:   "Clear the cache of a source (github, gitlab, tmuxinator, status, preview) or of all sources",
+		Args:    cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			github, _ := cmd.Flags().GetBool("github")
-			
-			if github || len(args) == 0 {
-				if err := clearGitHubCache(githubCache); err != nil {
-					return fmt.Errorf("failed to clear GitHub cache: %w", err)
-				}
-				fmt.Println("✅ GitHub cache cleared")
+			source := ""
+			if len(args) > 0 {
+				source = args[0]
+			} else if cmd.Flags().Changed("github") {
+				source = github.CacheSource
+			}
+
+			if err := cache.Clear(source); err != nil {
+				return fmt.Errorf("failed to clear cache: %w", err)
 			}
-			
+			if source == "" {
+				fmt.Println("✅ Cache cleared")
+			} else {
+				fmt.Printf("✅ %s cache cleared\n", source)
+			}
+
 			return nil
 		},
 	}
 
-	cmd.Flags().BoolP("github", "g", true, "clear GitHub cache")
+	cmd.Flags().BoolP("github", "g", false, "only clear the GitHub cache")
 
 	return cmd
 }
 
-func NewCacheInfoCommand(githubCache github.Cache) *cobra.Command {
+func NewCacheInfoCommand(cache cache.Cache, githubRateLimits github.RateLimits) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "info",
+		Use:   "info [source]",
 		Short: "Show cache information",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cachePath := githubCache.GetCachePath()
-			fmt.Printf("Cache directory: %s\n", cachePath)
-			
-			if _, err := os.Stat(cachePath); os.IsNotExist(err) {
-				fmt.Println("Cache directory does not exist")
-				return nil
+			source := ""
+			if len(args) > 0 {
+				source = args[0]
 			}
-			
-			files, err := os.ReadDir(cachePath)
+			entries, err := cache.Entries(source)
 			if err != nil {
-				return fmt.Errorf("failed to read cache directory: %w", err)
+				return err
 			}
-			
-			if len(files) == 0 {
-				fmt.Println("No cache files found")
+			rateLimits := githubRateLimits.All()
+
+			if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+				if rateLimits == nil {
+					rateLimits = []model.GitHubRateLimit{}
+				}
+				jsonData, err := json.MarshalIndent(map[string]any{
+					"entries":            entries,
+					"github_rate_limits": rateLimits,
+				}, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal cache entries: %w", err)
+				}
+				fmt.Println(string(jsonData))
 				return nil
 			}
-			
-			fmt.Printf("Cache files (%d):\n", len(files))
-			for _, file := range files {
-				if !file.IsDir() && filepath.Ext(file.Name()) == ".json" {
-					info, _ := file.Info()
-					orgName := file.Name()[:len(file.Name())-5] // Remove .json extension
-					fmt.Printf("  %s (modified: %s)\n", orgName, info.ModTime().Format("2006-01-02 15:04:05"))
+
+			fmt.Printf("Cache directory: %s\n", cache.Dir(""))
+			if len(entries) == 0 {
+				fmt.Println("No cache files found")
+			} else {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "SOURCE\tSCOPE\tSIZE\tCACHED AT\tEXPIRES AT\tHITS")
+				for _, entry := range entries {
+					expires := entry.ExpiresAt.Format(time.DateTime)
+					if entry.Expired() {
+						expires += " (expired)"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n", entry.Source, entry.Scope, formatSize(entry.Size), entry.CachedAt.Format(time.DateTime), expires, entry.Hits)
+				}
+				if err := w.Flush(); err != nil {
+					return err
 				}
 			}
-			
+
+			if len(rateLimits) > 0 {
+				fmt.Println()
+				printRateLimits(rateLimits)
+			}
 			return nil
 		},
 	}
 
+	cmd.Flags().Bool("json", false, "output the entries as json")
+
 	return cmd
 }
 
-func clearGitHubCache(githubCache github.Cache) error {
-	cachePath := githubCache.GetCachePath()
-	
-	if _, err := os.Stat(cachePath); os.IsNotExist(err) {
-		return nil // Cache directory doesn't exist, nothing to clear
-	}
-	
-	files, err := os.ReadDir(cachePath)
-	if err != nil {
-		return err
+func NewCacheRefreshCommand(config model.Config, githubLister lister.GitHub) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "Revalidate the GitHub cache",
+		Long:  "Revalidate the GitHub cache with conditional requests, unchanged repositories aren't downloaded again. sesh runs this in the background when the cache has expired.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := githubLister.ListAllReposWithOptions(config.GitHub, lister.FetchOptions{Refresh: true})
+			if err != nil {
+				return fmt.Errorf("failed to refresh GitHub cache: %w", err)
+			}
+			for _, key := range slices.Sorted(maps.Keys(results)) {
+				fmt.Printf("%s: %d repos\n", key, len(results[key]))
+			}
+			return nil
+		},
 	}
-	
-	for _, file := range files {
-		if !file.IsDir() && filepath.Ext(file.Name()) == ".json" {
-			filePath := filepath.Join(cachePath, file.Name())
-			if err := os.Remove(filePath); err != nil {
-				return fmt.Errorf("failed to remove cache file %s: %w", file.Name(), err)
+
+	return cmd
+}
+
+func NewCacheWarmCommand(l lister.Lister) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "warm",
+		Short: "Fill the cache of the slow sources",
+		Long:  "List the GitHub, GitLab and tmuxinator sources so that the next `sesh list` is served from the cache, e.g. from a login script.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessions, err := l.List(lister.ListOptions{GitHub: true, GitLab: true, Tmuxinator: true, Refresh: true})
+			if err != nil {
+				return fmt.Errorf("failed to warm cache: %w", err)
 			}
+			fmt.Printf("✅ Cached %d sessions\n", len(sessions.OrderedIndex))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// printRateLimits prints the GitHub quota of each token as of its last response
+func printRateLimits(rateLimits []model.GitHubRateLimit) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GITHUB HOST\tTOKEN\tREMAINING\tRESETS AT\tCHECKED AT")
+	now := time.Now()
+	for _, limit := range rateLimits {
+		token := limit.Token
+		if token == "" {
+			token = "none"
+		}
+		remaining := fmt.Sprintf("%d/%d", limit.Remaining, limit.Limit)
+		if until, limited := limit.Limited(now); limited {
+			remaining += fmt.Sprintf(" (limited until %s)", until.Local().Format(time.TimeOnly))
 		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", limit.Host, token, remaining, limit.Reset.Local().Format(time.DateTime), limit.CheckedAt.Local().Format(time.DateTime))
+	}
+	w.Flush()
+}
+
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
 	}
-	
-	return nil
 }
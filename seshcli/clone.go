@@ -1,30 +1,90 @@
 package seshcli
 
 import (
+	"bufio"
 	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/joshmedeski/sesh/v2/cloner"
+	"github.com/joshmedeski/sesh/v2/lister"
 	"github.com/joshmedeski/sesh/v2/model"
 )
 
-func NewCloneCommand(c cloner.Cloner) *cobra.Command {
+func NewCloneCommand(config model.Config, c cloner.Cloner, githubLister lister.GitHub) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "clone",
 		Aliases: []string{"cl"},
 		Short:   "Clone a git repo and connect to it as a session",
-		Args:    cobra.ExactArgs(1),
+		Long:    "Clone a git repo and connect to it as a session. With --org or --from-file, clone several repos concurrently without connecting to them",
+		Args:    cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) != 1 {
+			org, _ := cmd.Flags().GetString("org")
+			fromFile, _ := cmd.Flags().GetString("from-file")
+			bulk := org != "" || fromFile != ""
+			if bulk && len(args) > 0 {
+				return errors.New("--org and --from-file don't take a url to clone")
+			}
+			if !bulk && len(args) != 1 {
 				return errors.New("please provide url to clone")
 			}
-			repo := args[0]
 
 			cmdDir, _ := cmd.Flags().GetString("cmdDir")
 			dir, _ := cmd.Flags().GetString("dir")
 
-			opts := model.GitCloneOptions{CmdDir: cmdDir, Repo: repo, Dir: dir}
+			depth, _ := cmd.Flags().GetInt("depth")
+			branch, _ := cmd.Flags().GetString("branch")
+			recurseSubmodules, _ := cmd.Flags().GetBool("recurse-submodules")
+			filter, _ := cmd.Flags().GetString("filter")
+			bareWorktree, _ := cmd.Flags().GetBool("bare-worktree")
+			fetch, _ := cmd.Flags().GetBool("fetch")
+			noHooks, _ := cmd.Flags().GetBool("no-hooks")
+
+			opts := model.GitCloneOptions{
+				CmdDir:       cmdDir,
+				Dir:          dir,
+				BareWorktree: bareWorktree,
+				Fetch:        fetch,
+				NoHooks:      noHooks,
+				GitCloneFlags: model.GitCloneFlags{
+					Depth:             depth,
+					Branch:            branch,
+					RecurseSubmodules: recurseSubmodules,
+					Filter:            filter,
+				},
+			}
+			if bulk {
+				if dir != "" {
+					return errors.New("--dir names a single repo, use --cmdDir with --org or --from-file")
+				}
+				var repos []string
+				var err error
+				if org != "" {
+					repos, err = listOrgRepos(cmd, config.GitHub, githubLister, org)
+				} else {
+					repos, err = readRepoList(fromFile)
+				}
+				if err != nil {
+					return err
+				}
+				jobs, _ := cmd.Flags().GetInt("jobs")
+				return cloneAll(c, repos, opts, jobs)
+			}
+
+			opts.Repo = args[0]
+			if printPath, _ := cmd.Flags().GetBool("print-path"); printPath {
+				path, err := c.ClonePath(opts)
+				if err != nil {
+					return err
+				}
+				fmt.Println(path)
+				return nil
+			}
+
 			if _, err := c.Clone(opts); err != nil {
 				return err
 			} else {
@@ -35,6 +95,129 @@ func NewCloneCommand(c cloner.Cloner) *cobra.Command {
 
 	cmd.Flags().StringP("cmdDir", "c", "", "The directory to run the git command in")
 	cmd.Flags().StringP("dir", "d", "", "The name of the directory that git is creating")
+	// The defaults come from the [clone] config
+	cmd.Flags().Int("depth", config.Clone.Depth, "Create a shallow clone with this many commits")
+	cmd.Flags().StringP("branch", "b", "", "The branch to check out")
+	cmd.Flags().Bool("recurse-submodules", config.Clone.RecurseSubmodules, "Clone the submodules too")
+	cmd.Flags().String("filter", config.Clone.Filter, "Partial clone filter, e.g. blob:none")
+	cmd.Flags().Bool("print-path", false, "Print where the repo would be cloned to without cloning it")
+	cmd.Flags().Bool("fetch", false, "Fetch the repo when it's already cloned")
+	cmd.Flags().Bool("no-hooks", false, "Don't run the post_clone hooks")
+	cmd.Flags().Bool("bare-worktree", config.Clone.BareWorktree, "Clone into <repo>/.bare and check the branch out in a worktree next to it")
+	cmd.Flags().String("org", "", "Clone the repos of a GitHub organization or user")
+	cmd.Flags().String("from-file", "", "Clone the repos listed in a file, one url or shorthand per line, - reads stdin")
+	cmd.Flags().IntP("jobs", "j", cloner.DefaultJobs, "How many repos to clone at once with --org or --from-file")
+	// Repos of --org are also filtered by the [github] config of the organization
+	cmd.Flags().StringSlice("language", nil, "Only clone the repos of --org with one of these primary languages")
+	cmd.Flags().StringSlice("topic", nil, "Only clone the repos of --org with one of these topics")
+	cmd.Flags().String("name-pattern", "", "Only clone the repos of --org with names matching this regular expression")
 
 	return cmd
 }
+
+// listOrgRepos lists the repos of an organization from the GitHub cache, with
+// the filters of the config and the command line
+func listOrgRepos(cmd *cobra.Command, config model.GitHubConfig, githubLister lister.GitHub, org string) ([]string, error) {
+	repos, err := githubLister.ListOrgRepos(config, org, lister.FetchOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	orgConfig, ok := config.GetOrgConfig(org)
+	if !ok {
+		orgConfig = model.GitHubOrgConfig{Name: org}
+	}
+	var override model.GitHubRepoFilter
+	override.Languages, _ = cmd.Flags().GetStringSlice("language")
+	override.Topics, _ = cmd.Flags().GetStringSlice("topic")
+	override.NamePattern, _ = cmd.Flags().GetString("name-pattern")
+	matcher, err := config.GetRepoFilter(orgConfig).Override(override).Matcher(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, repo := range repos {
+		if repo.Archived || repo.Disabled || !matcher.Match(repo) {
+			continue
+		}
+		names = append(names, org+"/"+repo.Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no repos of %s to clone", org)
+	}
+	return names, nil
+}
+
+// readRepoList reads one repo per line, blank lines and # comments are skipped
+func readRepoList(path string) ([]string, error) {
+	file := os.Stdin
+	if path != "-" {
+		var err error
+		if file, err = os.Open(path); err != nil {
+			return nil, fmt.Errorf("couldn't read repos: %w", err)
+		}
+		defer file.Close()
+	}
+
+	var repos []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		repos = append(repos, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't read repos: %w", err)
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no repos to clone in %s", path)
+	}
+	return repos, nil
+}
+
+// cloneAll clones repos into their clone paths, printing progress and a
+// summary of the failures
+func cloneAll(c cloner.Cloner, repos []string, opts model.GitCloneOptions, jobs int) error {
+	// Progress of concurrent clones would be interleaved
+	opts.Quiet = true
+	all := make([]model.GitCloneOptions, len(repos))
+	for i, repo := range repos {
+		all[i] = opts
+		all[i].Repo = repo
+	}
+
+	results := c.CloneAll(all, jobs, func(done int, result model.CloneResult) {
+		status := "cloned"
+		switch {
+		case result.Err != nil:
+			status = "failed"
+		case result.Skipped:
+			status = "skipped"
+		}
+		fmt.Printf("[%*d/%d] %-7s %s\n", len(fmt.Sprint(len(all))), done, len(all), status, result.Repo)
+	})
+
+	var cloned, skipped int
+	var failed []model.CloneResult
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed = append(failed, result)
+		case result.Skipped:
+			skipped++
+		default:
+			cloned++
+		}
+	}
+	fmt.Printf("\nCloned %d, skipped %d already cloned, failed %d\n", cloned, skipped, len(failed))
+	for _, result := range failed {
+		fmt.Printf("  %s: %s\n", result.Repo, result.Err)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to clone %d of %d repos", len(failed), len(all))
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package seshcli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/joshmedeski/sesh/v2/configurator"
+	"github.com/joshmedeski/sesh/v2/localconfig"
+)
+
+func NewConfigCommand(c configurator.Configurator, l localconfig.LocalConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage sesh config",
+		// The config commands must work even when the config can't be loaded
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+
+	// Add subcommands
+	cmd.AddCommand(
+		NewConfigValidateCommand(c),
+		NewConfigShowCommand(c),
+		NewConfigPathCommand(c),
+		NewConfigEditCommand(c),
+		NewConfigSchemaCommand(),
+		NewConfigTrustCommand(l),
+	)
+
+	return cmd
+}
+
+func NewConfigValidateCommand(c configurator.Configurator) *cobra.Command {
+	return &cobra.Command{
+		Use:     "validate",
+		Aliases: []string{"check"},
+		Short:   "Validate the config file and its imports",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return validateConfig(c)
+		},
+	}
+}
+
+func NewConfigShowCommand(c configurator.Configurator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the resolved config, including imports",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+
+			config, err := c.GetConfig()
+			if err != nil {
+				return fmt.Errorf("couldn't load config: %w", err)
+			}
+
+			data, err := toml.Marshal(config)
+			if err != nil {
+				return fmt.Errorf("couldn't encode config: %w", err)
+			}
+
+			if !jsonOutput {
+				fmt.Print(string(data))
+				return nil
+			}
+
+			// Round-trip through TOML so the JSON keys match the config file
+			resolved := make(map[string]any)
+			if err := toml.Unmarshal(data, &resolved); err != nil {
+				return fmt.Errorf("couldn't encode config: %w", err)
+			}
+			jsonData, err := json.MarshalIndent(resolved, "", "  ")
+			if err != nil {
+				return fmt.Errorf("couldn't encode config as json: %w", err)
+			}
+			fmt.Println(string(jsonData))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolP("json", "j", false, "output as json")
+
+	return cmd
+}
+
+func NewConfigPathCommand(c configurator.Configurator) *cobra.Command {
+	return &cobra.Command{
+		Use:   "path",
+		Short: "Show the config files that are loaded",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files, err := c.ConfigFiles()
+			for _, file := range files {
+				switch {
+				case !file.Exists:
+					fmt.Printf("%s (not found)\n", file.Path)
+				case file.Import:
+					fmt.Printf("%s (import)\n", file.Path)
+				default:
+					fmt.Println(file.Path)
+				}
+			}
+			return err
+		},
+	}
+}
+
+func NewConfigEditCommand(c configurator.Configurator) *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit",
+		Short: "Open the config file in $EDITOR and validate it afterwards",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files, err := c.ConfigFiles()
+			if len(files) == 0 {
+				return err
+			}
+
+			if err := os.MkdirAll(filepath.Dir(files[0].Path), 0755); err != nil {
+				return fmt.Errorf("failed to create config directory: %w", err)
+			}
+
+			editor := os.Getenv("EDITOR")
+			if editor == "" {
+				editor = "vi"
+			}
+
+			// The editor needs the terminal, so it can't go through the shell wrapper
+			command := exec.Command(editor, files[0].Path)
+			command.Stdin = os.Stdin
+			command.Stdout = os.Stdout
+			command.Stderr = os.Stderr
+			if err := command.Run(); err != nil {
+				return fmt.Errorf("couldn't run editor %s: %w", editor, err)
+			}
+
+			return validateConfig(c)
+		},
+	}
+}
+
+func NewConfigSchemaCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print a JSON Schema of the config file for editor completion",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := configurator.Schema()
+			if err != nil {
+				return fmt.Errorf("couldn't generate schema: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		},
+	}
+}
+
+func validateConfig(c configurator.Configurator) error {
+	errs := c.Validate()
+	if len(errs) == 0 {
+		fmt.Println("✅ Config is valid")
+		return nil
+	}
+
+	for _, err := range errs {
+		fmt.Printf("❌ %s\n", err)
+		var human *configurator.ConfigError
+		if errors.As(err, &human) && human.Human() != "" {
+			fmt.Printf("%s\n", human.Human())
+		}
+	}
+	return fmt.Errorf("found %d problem(s) in config", len(errs))
+}
+
+func NewConfigTrustCommand(l localconfig.LocalConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "trust [dir]",
+		Short: "Allow the commands in a repo-local " + localconfig.FileName + " to run",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				dir, err = filepath.Abs(args[0])
+				if err != nil {
+					return err
+				}
+			}
+
+			file, err := l.Trust(dir)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅ Trusted %s\n", file)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package seshcli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joshmedeski/sesh/v2/github"
+)
+
+func NewDoctorCommand(githubRateLimits github.RateLimits) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the health of sesh",
+		Long:  "Show the remaining GitHub quota of each host and token as of their last response.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rateLimits := githubRateLimits.All()
+			if len(rateLimits) == 0 {
+				fmt.Println("No GitHub rate limits recorded yet")
+				return nil
+			}
+			printRateLimits(rateLimits)
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,64 @@
+package seshcli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/rules"
+)
+
+func NewExplainRulesCommand(r rules.Rules, home home.Home) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explain-rules <path>",
+		Short: "Show which rule applies to a session path",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, _ := cmd.Flags().GetString("src")
+
+			path, err := home.ExpandHome(args[0])
+			if err != nil {
+				return err
+			}
+			path, err = filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+
+			explanations, err := r.Explain(model.SeshSession{Src: src, Name: filepath.Base(path), Path: path})
+			if err != nil {
+				return err
+			}
+			if len(explanations) == 0 {
+				fmt.Println("No rules are defined")
+				return nil
+			}
+
+			matched := false
+			for _, explanation := range explanations {
+				status := "❌"
+				if explanation.Matched && !matched {
+					status = "✅"
+					matched = true
+				} else if explanation.Matched {
+					status = "⏭️ " // matches, but an earlier rule wins
+				}
+				fmt.Printf("%s %s\n", status, explanation.Rule.Name)
+				for _, reason := range explanation.Reasons {
+					fmt.Printf("   %s\n", reason)
+				}
+			}
+			if !matched {
+				fmt.Println("No rule matches, [default_session] is used")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("src", "s", "dir", "source of the session (zoxide, dir, github, ...)")
+
+	return cmd
+}
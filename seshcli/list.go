@@ -25,8 +25,10 @@ func NewListCommand(icon icon.Icon, json json.Json, list lister.Lister) *cobra.C
 			icons, _ := cmd.Flags().GetBool("icons")
 			tmuxinator, _ := cmd.Flags().GetBool("tmuxinator")
 			github, _ := cmd.Flags().GetBool("github")
+			gitlab, _ := cmd.Flags().GetBool("gitlab")
 			hideDuplicates, _ := cmd.Flags().GetBool("hide-duplicates")
 			refresh, _ := cmd.Flags().GetBool("refresh")
+			offline, _ := cmd.Flags().GetBool("offline")
 
 			sessions, err := list.List(lister.ListOptions{
 				Config:         config,
@@ -37,8 +39,10 @@ func NewListCommand(icon icon.Icon, json json.Json, list lister.Lister) *cobra.C
 				Zoxide:         zoxide,
 				Tmuxinator:     tmuxinator,
 				GitHub:         github,
+				GitLab:         gitlab,
 				HideDuplicates: hideDuplicates,
 				Refresh:        refresh,
+				Offline:        offline,
 			})
 			if err != nil {
 				return fmt.Errorf("couldn't list sessions: %q", err)
@@ -73,8 +77,10 @@ func NewListCommand(icon icon.Icon, json json.Json, list lister.Lister) *cobra.C
 	cmd.Flags().BoolP("icons", "i", false, "show icons")
 	cmd.Flags().BoolP("tmuxinator", "T", false, "show tmuxinator configs")
 	cmd.Flags().BoolP("github", "g", false, "show GitHub organization repositories")
+	cmd.Flags().BoolP("gitlab", "L", false, "show GitLab group projects")
 	cmd.Flags().BoolP("hide-duplicates", "d", false, "hide duplicate entries")
-	cmd.Flags().BoolP("refresh", "r", false, "force refresh GitHub cache")
+	cmd.Flags().BoolP("refresh", "r", false, "force refresh GitHub and GitLab cache")
+	cmd.Flags().Bool("offline", false, "only use cached GitHub repositories, even when they have expired")
 
 	return cmd
 }
@@ -0,0 +1,46 @@
+package seshcli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joshmedeski/sesh/v2/pullrequest"
+)
+
+func NewPRCommand(pr pullrequest.PullRequest) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pr <org/repo#123 | url>",
+		Short: "Check out a pull request in a worktree and connect to it as a session",
+		Long:  "Check out a pull request in a worktree and connect to it as a session. With --list, print the open pull requests of org/repo to pick one from, e.g. sesh pr \"$(sesh pr --list org/repo | fzf)\"",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, _ := cmd.Flags().GetBool("list")
+
+			if !list {
+				out, err := pr.Open(args[0])
+				if out != "" {
+					fmt.Println(out)
+				}
+				return err
+			}
+
+			pullRequests, err := pr.List(args[0])
+			if err != nil {
+				return err
+			}
+			for _, pullRequest := range pullRequests {
+				draft := ""
+				if pullRequest.Draft {
+					draft = " [draft]"
+				}
+				fmt.Printf("%s#%d %s (%s)%s\n", pullRequest.Repo, pullRequest.Number, pullRequest.Title, pullRequest.Author, draft)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolP("list", "l", false, "list the open pull requests of org/repo")
+
+	return cmd
+}
@@ -4,9 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/joshmedeski/sesh/v2/cache"
 	"github.com/joshmedeski/sesh/v2/cloner"
 	"github.com/joshmedeski/sesh/v2/configurator"
 	"github.com/joshmedeski/sesh/v2/connector"
@@ -14,25 +17,34 @@ import (
 	"github.com/joshmedeski/sesh/v2/execwrap"
 	"github.com/joshmedeski/sesh/v2/git"
 	"github.com/joshmedeski/sesh/v2/github"
+	"github.com/joshmedeski/sesh/v2/gitlab"
 	"github.com/joshmedeski/sesh/v2/home"
+	"github.com/joshmedeski/sesh/v2/hooks"
 	"github.com/joshmedeski/sesh/v2/icon"
 	"github.com/joshmedeski/sesh/v2/json"
 	"github.com/joshmedeski/sesh/v2/lister"
+	"github.com/joshmedeski/sesh/v2/localconfig"
 	"github.com/joshmedeski/sesh/v2/ls"
 	"github.com/joshmedeski/sesh/v2/namer"
 	"github.com/joshmedeski/sesh/v2/oswrap"
 	"github.com/joshmedeski/sesh/v2/pathwrap"
 	"github.com/joshmedeski/sesh/v2/previewer"
+	"github.com/joshmedeski/sesh/v2/pullrequest"
 	"github.com/joshmedeski/sesh/v2/replacer"
+	"github.com/joshmedeski/sesh/v2/rules"
 	"github.com/joshmedeski/sesh/v2/runtimewrap"
 	"github.com/joshmedeski/sesh/v2/shell"
 	"github.com/joshmedeski/sesh/v2/startup"
+	"github.com/joshmedeski/sesh/v2/status"
 	"github.com/joshmedeski/sesh/v2/tmux"
 	"github.com/joshmedeski/sesh/v2/tmuxinator"
 	"github.com/joshmedeski/sesh/v2/zoxide"
 )
 
 func NewRootCommand(version string) *cobra.Command {
+	// the config is loaded before cobra parses the flags
+	configPath := configFlag(os.Args[1:])
+
 	// wrapper dependencies
 	exec := execwrap.NewExec()
 	os := oswrap.NewOs()
@@ -43,50 +55,80 @@ func NewRootCommand(version string) *cobra.Command {
 	home := home.NewHome(os)
 	shell := shell.NewShell(exec, home)
 	json := json.NewJson()
-	replacer := replacer.NewReplacer()
+	cache := cache.NewCache(home)
 
 	// resource dependencies
 	git := git.NewGit(shell)
+	replacer := replacer.NewReplacer(os, git)
 	dir := dir.NewDir(os, git, path)
 	tmux := tmux.NewTmux(os, shell)
 	zoxide := zoxide.NewZoxide(shell)
-	tmuxinator := tmuxinator.NewTmuxinator(shell)
+	tmuxinator := tmuxinator.NewTmuxinator(os, home, shell, cache)
 
 	// config
-	config, err := configurator.NewConfigurator(os, path, runtime).GetConfig()
-	// TODO: make sure to ignore the error if the config doesn't exist
-	if err != nil {
-		var human *configurator.ConfigError
-		if errors.As(err, &human) {
-			// No panic here because it leads to panic in the end of the root branch anyway.
-			fmt.Printf("Couldn't parse config, err: %v\n details:\n %s\n", err.Error(), human.Human())
-		}
-		slog.Error("seshcli/root_command.go: NewRootCommand", "error", err)
-		panic(err)
+	cfg := configurator.NewConfigurator(os, path, runtime, configPath)
+	config, configErr := cfg.GetConfig()
+	if configErr != nil {
+		slog.Error("seshcli/root_command.go: NewRootCommand", "error", configErr)
 	}
 
 	slog.Debug("seshcli/root_command.go: NewRootCommand", "version", version, "config", config)
+	tokens := configurator.NewTokenResolver(os, home, shell)
 
 	// github dependencies
-	githubClient := github.NewClient(config.GitHub.Token)
-	githubCache := github.NewCache(home)
-	githubLister := lister.NewGitHub(githubClient, githubCache)
+	githubRateLimits := github.NewRateLimits(cache)
+	githubClient := github.NewClient(config.GitHub.Token, githubRateLimits)
+	githubCache := github.NewCache(cache)
+	configFilePath, err := cfg.ConfigPath()
+	if err != nil {
+		slog.Error("seshcli/root_command.go: NewRootCommand", "error", err)
+	}
+	githubLister := lister.NewGitHub(githubClient, githubCache, github.NewRefresher(githubCache, configFilePath), tokens)
+
+	// gitlab dependencies
+	gitlabLister := lister.NewGitLab(gitlab.NewClient(config.GitLab, tokens), gitlab.NewCache(cache))
 
 	// core dependencies
-	ls := ls.NewLs(config, shell)
-	lister := lister.NewLister(config, home, tmux, zoxide, tmuxinator, githubLister)
-	startup := startup.NewStartup(config, lister, tmux, home, replacer)
+	ls := ls.NewLs(config, shell, replacer)
+	lister := lister.NewLister(config, home, tmux, zoxide, tmuxinator, githubLister, gitlabLister)
+	localConfig := localconfig.NewLocalConfig(config, os, home, git, localconfig.NewTrustStore(os, home))
+	rules := rules.NewRules(config, os, home, git)
+	startup := startup.NewStartup(config, lister, tmux, home, replacer, localConfig, rules)
 	namer := namer.NewNamer(path, git, home)
-	connector := connector.NewConnector(config, dir, git, home, lister, namer, startup, tmux, zoxide, tmuxinator)
+	hooks := hooks.NewHooks(config, shell, git, home)
+	connector := connector.NewConnector(config, dir, git, home, lister, namer, startup, tmux, zoxide, tmuxinator, hooks)
 	icon := icon.NewIcon(config)
-	previewer := previewer.NewPreviewer(lister, tmux, icon, dir, home, ls, config, shell)
-	cloner := cloner.NewCloner(connector, git, config)
+	previewer := previewer.NewPreviewer(lister, tmux, icon, dir, home, ls, config, shell, replacer, rules, cache)
+	cloner := cloner.NewCloner(connector, git, home, config, hooks)
+	pr := pullrequest.NewPullRequest(os, connector, git, home, githubClient, tokens, config)
+	status := status.NewStatus(config, tmux, git, icon, lister, status.NewCache(cache))
 
 	rootCmd := &cobra.Command{
 		Use:     "sesh",
 		Version: version,
 		Short:   "Smart session manager for the terminal",
 		Long:    "Sesh is a smart terminal session manager that helps you create and manage tmux sessions quickly and easily using zoxide.",
+		// Every command but `sesh config` needs a valid config
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if configErr != nil {
+				var human *configurator.ConfigError
+				if errors.As(configErr, &human) {
+					return fmt.Errorf("couldn't parse config: %w\n details:\n %s", configErr, human.Human())
+				}
+				return fmt.Errorf("couldn't load config (run `sesh config validate` for details): %w", configErr)
+			}
+			return nil
+		},
+		// Cache hits and GitHub rate limits are kept in memory, they're saved
+		// once per run
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			if err := cache.Flush(); err != nil {
+				slog.Debug("seshcli/root_command.go: NewRootCommand", "error", err)
+			}
+			if err := githubRateLimits.Flush(); err != nil {
+				slog.Debug("seshcli/root_command.go: NewRootCommand", "error", err)
+			}
+		},
 	}
 
 	// Add subcommands
@@ -94,12 +136,37 @@ func NewRootCommand(version string) *cobra.Command {
 		NewListCommand(icon, json, lister),
 		NewLastCommand(lister, tmux),
 		NewConnectCommand(connector, icon, dir),
-		NewCloneCommand(cloner),
+		NewCloneCommand(config, cloner, githubLister),
+		NewPRCommand(pr),
 		NewRootSessionCommand(lister, namer),
 		NewPreviewCommand(previewer),
-		NewCacheCommand(githubCache),
+		NewCacheCommand(config, cache, githubRateLimits, githubLister, lister),
+		NewStatusCommand(status),
+		NewConfigCommand(cfg, localConfig),
+		NewExplainRulesCommand(rules, home),
+		NewStartupStepsCommand(startup),
+		NewDoctorCommand(githubRateLimits),
 	)
 
+	rootCmd.PersistentFlags().StringP("config", "C", "", "path to the config file (defaults to $SESH_CONFIG or $XDG_CONFIG_HOME/sesh/sesh.toml)")
+
 	return rootCmd
 }
 
+// configFlag returns the value of the --config flag from the raw arguments
+func configFlag(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		for _, flag := range []string{"--config", "-C"} {
+			if arg == flag && i+1 < len(args) {
+				return args[i+1]
+			}
+			if value, ok := strings.CutPrefix(arg, flag+"="); ok {
+				return value
+			}
+		}
+	}
+	return ""
+}
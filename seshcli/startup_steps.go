@@ -0,0 +1,32 @@
+package seshcli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/startup"
+)
+
+// NewStartupStepsCommand runs startup steps that wait for the session to be
+// ready, sesh connect starts it with tmux run-shell so it doesn't block
+func NewStartupStepsCommand(s startup.Startup) *cobra.Command {
+	return &cobra.Command{
+		Use:           "startup-steps <session> <steps>",
+		Short:         "Run the startup steps of a session in the background",
+		Hidden:        true,
+		Args:          cobra.ExactArgs(2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var steps model.StartupCommand
+			if err := json.Unmarshal([]byte(args[1]), &steps); err != nil {
+				return fmt.Errorf("couldn't decode startup steps: %w", err)
+			}
+			// Failures are shown in the session, the exit status tells tmux
+			return s.RunBackgroundSteps(args[0], steps)
+		},
+	}
+}
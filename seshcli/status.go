@@ -0,0 +1,38 @@
+package seshcli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joshmedeski/sesh/v2/status"
+)
+
+func NewStatusCommand(s status.Status) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status [session]",
+		Short: "Show a summary of the session for the tmux status line",
+		Long:  "Show a summary of the given (or attached) session for the tmux status line, e.g. set -g status-right '#(sesh status \"#S\")'",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) == 1 {
+				name = args[0]
+			}
+			format, _ := cmd.Flags().GetString("format")
+
+			output, err := s.Render(name, format)
+			if err != nil {
+				return err
+			}
+
+			fmt.Print(output)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("format", "f", "", "Go template used to render the status (overrides the config)")
+
+	return cmd
+}
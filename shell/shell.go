@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/charmbracelet/x/term"
 	"github.com/joshmedeski/sesh/v2/execwrap"
 	"github.com/joshmedeski/sesh/v2/home"
 )
@@ -14,15 +15,27 @@ type Shell interface {
 	Cmd(cmd string, arg ...string) (string, error)
 	ListCmd(cmd string, arg ...string) ([]string, error)
 	PrepareCmd(cmd string, replacements map[string]string) ([]string, error)
+	// Stream runs a long command with its progress shown in the terminal, or
+	// in a tmux popup when sesh runs in tmux without one, the title names the
+	// step. Without either the output is captured like Cmd.
+	Stream(title string, cmd string, arg ...string) error
 }
 
 type RealShell struct {
 	exec execwrap.Exec
 	home home.Home
+	// terminal reports whether stderr is a terminal progress can be shown in
+	terminal func() bool
+	getenv   func(key string) string
 }
 
 func NewShell(exec execwrap.Exec, home home.Home) Shell {
-	return &RealShell{exec, home}
+	return &RealShell{
+		exec:     exec,
+		home:     home,
+		terminal: func() bool { return term.IsTerminal(os.Stderr.Fd()) },
+		getenv:   os.Getenv,
+	}
 }
 
 func (c *RealShell) Cmd(cmd string, args ...string) (string, error) {
@@ -0,0 +1,80 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// popupScript runs a command in a tmux popup and writes its exit status to a
+// file, the popup stays open on failures so the output can be read
+const popupScript = `status_file=$1
+shift
+"$@"
+status=$?
+if [ "$status" -ne 0 ]; then
+  printf '\nFailed with exit status %s, press enter to close' "$status"
+  read -r _
+fi
+echo "$status" > "$status_file"`
+
+func (c *RealShell) Stream(title string, cmd string, args ...string) error {
+	foundCmd, err := c.exec.LookPath(cmd)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case c.terminal != nil && c.terminal():
+		// Progress goes to stderr, stdout is left to the output of sesh
+		command := exec.Command(foundCmd, args...)
+		command.Stdin = os.Stdin
+		command.Stdout = os.Stderr
+		command.Stderr = os.Stderr
+		return command.Run()
+	case c.getenv != nil && c.getenv("TMUX") != "":
+		return c.streamToPopup(title, foundCmd, args)
+	default:
+		_, err := c.Cmd(foundCmd, args...)
+		return err
+	}
+}
+
+// streamToPopup runs the command in a tmux popup, display-popup returns once
+// the popup is closed
+func (c *RealShell) streamToPopup(title string, cmd string, args []string) error {
+	statusFile, err := os.CreateTemp("", "sesh-status-*")
+	if err != nil {
+		return err
+	}
+	statusFile.Close()
+	defer os.Remove(statusFile.Name())
+
+	var shellCmd []string
+	for _, arg := range append([]string{"sh", "-c", popupScript, "sh", statusFile.Name(), cmd}, args...) {
+		shellCmd = append(shellCmd, Quote(arg))
+	}
+	if _, err := c.Cmd("tmux", "display-popup", "-E", "-w", "80%", "-h", "50%", "-T", " "+title+" ", strings.Join(shellCmd, " ")); err != nil {
+		return err
+	}
+
+	out, err := os.ReadFile(statusFile.Name())
+	if err != nil {
+		return err
+	}
+	status, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return fmt.Errorf("%s was interrupted", title)
+	}
+	if status != 0 {
+		return fmt.Errorf("exit status %d", status)
+	}
+	return nil
+}
+
+// Quote quotes an argument for sh
+func Quote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
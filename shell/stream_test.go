@@ -0,0 +1,46 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/execwrap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShellStream(t *testing.T) {
+	newShell := func(terminal bool, tmux string) *RealShell {
+		return &RealShell{
+			exec:     execwrap.NewExec(),
+			terminal: func() bool { return terminal },
+			getenv:   func(key string) string { return map[string]string{"TMUX": tmux}[key] },
+		}
+	}
+
+	t.Run("should capture the output without a terminal or tmux", func(t *testing.T) {
+		shell := newShell(false, "")
+		assert.Nil(t, shell.Stream("Succeeding", "sh", "-c", "echo progress >&2"))
+		assert.EqualError(t, shell.Stream("Failing", "sh", "-c", "exit 3"), "exit status 3")
+	})
+
+	t.Run("should stream to the terminal", func(t *testing.T) {
+		shell := newShell(true, "/tmp/tmux-501/default,1,0")
+		assert.Nil(t, shell.Stream("Succeeding", "sh", "-c", "true"))
+		assert.EqualError(t, shell.Stream("Failing", "sh", "-c", "exit 3"), "exit status 3")
+	})
+
+	t.Run("should stream to a tmux popup", func(t *testing.T) {
+		// The fake tmux runs the shell command of display-popup, its last argument
+		bin := t.TempDir()
+		fakeTmux := "#!/bin/sh\nfor last; do :; done\nexec sh -c \"$last\" </dev/null\n"
+		assert.Nil(t, os.WriteFile(filepath.Join(bin, "tmux"), []byte(fakeTmux), 0755))
+		t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+		marker := filepath.Join(t.TempDir(), "it's done")
+
+		shell := newShell(false, "/tmp/tmux-501/default,1,0")
+		assert.Nil(t, shell.Stream("Succeeding", "touch", marker))
+		assert.FileExists(t, marker)
+		assert.EqualError(t, shell.Stream("Failing", "sh", "-c", "exit 3"), "exit status 3")
+	})
+}
@@ -2,19 +2,15 @@ package startup
 
 import "github.com/joshmedeski/sesh/v2/model"
 
-func configStrategy(s *RealStartup, session model.SeshSession) (string, error) {
+func configStrategy(s *RealStartup, session model.SeshSession) (model.StartupCommand, error) {
 	config, exists := s.lister.FindConfigSession(session.Name)
 
 	if exists && config.Tmuxinator != "" {
-		return config.Tmuxinator, nil
+		return model.NewStartupCommand(config.Tmuxinator), nil
 	}
 
-	if exists && config.StartupCommand != "" {
-		replacements := map[string]string{
-			"{}": session.Path,
-		}
-
-		return s.replacer.Replace(config.StartupCommand, replacements), nil
+	if exists && len(config.StartupCommand) > 0 {
+		return config.StartupCommand, nil
 	}
-	return "", nil
+	return nil, nil
 }
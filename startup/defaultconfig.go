@@ -2,19 +2,10 @@ package startup
 
 import "github.com/joshmedeski/sesh/v2/model"
 
-func defaultConfigStrategy(s *RealStartup, session model.SeshSession) (string, error) {
+func defaultConfigStrategy(s *RealStartup, session model.SeshSession) (model.StartupCommand, error) {
 	if session.DisableStartupCommand {
-		return "", nil
+		return nil, nil
 	}
 
-	defaultConfig := s.config.DefaultSessionConfig
-	if defaultConfig.StartupCommand != "" {
-		replacements := map[string]string{
-			"{}": session.Path,
-		}
-
-		return s.replacer.Replace(defaultConfig.StartupCommand, replacements), nil
-	}
-
-	return "", nil
+	return s.config.DefaultSessionConfig.StartupCommand, nil
 }
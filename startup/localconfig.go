@@ -0,0 +1,19 @@
+package startup
+
+import "github.com/joshmedeski/sesh/v2/model"
+
+func localConfigStrategy(s *RealStartup, session model.SeshSession) (model.StartupCommand, error) {
+	if session.DisableStartupCommand {
+		return nil, nil
+	}
+
+	local, exists, err := s.localConfig.Load(session.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		return local.StartupCommand, nil
+	}
+	return nil, nil
+}
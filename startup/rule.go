@@ -0,0 +1,37 @@
+package startup
+
+import "github.com/joshmedeski/sesh/v2/model"
+
+func ruleStrategy(s *RealStartup, session model.SeshSession) (model.StartupCommand, error) {
+	if session.DisableStartupCommand {
+		return nil, nil
+	}
+
+	rule, exists, err := s.matchRule(session)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		return rule.StartupCommand, nil
+	}
+	return nil, nil
+}
+
+// matchRule finds the rule for sessions that aren't defined in the config
+func (s *RealStartup) matchRule(session model.SeshSession) (model.RuleConfig, bool, error) {
+	if session.Src == "config" {
+		return model.RuleConfig{}, false, nil
+	}
+	return s.rules.Match(session)
+}
+
+// Env returns the environment of the rule that matches the session, it's
+// passed to tmux new-session so every pane inherits it
+func (s *RealStartup) Env(session model.SeshSession) (map[string]string, error) {
+	rule, exists, err := s.matchRule(session)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return rule.Env, nil
+}
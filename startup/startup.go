@@ -2,40 +2,72 @@ package startup
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/joshmedeski/sesh/v2/home"
 	"github.com/joshmedeski/sesh/v2/lister"
+	"github.com/joshmedeski/sesh/v2/localconfig"
 	"github.com/joshmedeski/sesh/v2/model"
 	"github.com/joshmedeski/sesh/v2/replacer"
+	"github.com/joshmedeski/sesh/v2/rules"
 	"github.com/joshmedeski/sesh/v2/tmux"
 )
 
 type Startup interface {
 	Exec(session model.SeshSession) (string, error)
+	Env(session model.SeshSession) (map[string]string, error)
+	RunBackgroundSteps(name string, command model.StartupCommand) error
 }
 
 type RealStartup struct {
-	lister   lister.Lister
-	tmux     tmux.Tmux
-	config   model.Config
-	home     home.Home
-	replacer replacer.Replacer
+	lister      lister.Lister
+	tmux        tmux.Tmux
+	config      model.Config
+	home        home.Home
+	replacer    replacer.Replacer
+	localConfig localconfig.LocalConfig
+	rules       rules.Rules
 }
 
 func NewStartup(
-	config model.Config, lister lister.Lister, tmux tmux.Tmux, home home.Home, replacer replacer.Replacer,
+	config model.Config, lister lister.Lister, tmux tmux.Tmux, home home.Home, replacer replacer.Replacer, localConfig localconfig.LocalConfig, rules rules.Rules,
 ) Startup {
-	return &RealStartup{lister, tmux, config, home, replacer}
+	return &RealStartup{lister, tmux, config, home, replacer, localConfig, rules}
 }
 
 func (s *RealStartup) Exec(session model.SeshSession) (string, error) {
-	strategies := []func(*RealStartup, model.SeshSession) (string, error){
+	strategies := []func(*RealStartup, model.SeshSession) (model.StartupCommand, error){
 		configStrategy,
+		localConfigStrategy,
+		ruleStrategy,
 		defaultConfigStrategy,
 	}
 
+	local, hasLocal, err := s.localConfig.Load(session.Path)
+	if err != nil {
+		return "", fmt.Errorf("couldn't load local config: %w", err)
+	}
+	windowConfigs := s.config.WindowConfigs
+	windowNames := session.WindowNames
+	if hasLocal {
+		windowConfigs = append(slices.Clone(windowConfigs), local.WindowConfigs...)
+		if len(windowNames) == 0 {
+			windowNames = local.Windows
+		}
+	}
+
+	rule, hasRule, err := s.matchRule(session)
+	if err != nil {
+		return "", fmt.Errorf("couldn't match rules: %w", err)
+	}
+	if hasRule {
+		if len(windowNames) == 0 {
+			windowNames = rule.Windows
+		}
+	}
+
 	windows := make(model.SeshWindowMap)
-	for _, window := range s.config.WindowConfigs {
+	for _, window := range windowConfigs {
 		key := lister.ConfigKey(window.Name)
 		var path string = ""
 		var err error = nil
@@ -53,7 +85,7 @@ func (s *RealStartup) Exec(session model.SeshSession) (string, error) {
 		}
 	}
 
-	for _, window := range session.WindowNames {
+	for _, window := range windowNames {
 		windowConfig, ok := windows[lister.ConfigKey(window)]
 		if !ok {
 			return "", fmt.Errorf("window %s is not defined in config", window)
@@ -70,7 +102,11 @@ func (s *RealStartup) Exec(session model.SeshSession) (string, error) {
 		if ret, err := s.tmux.NewWindow(windowConfig.Path, windowConfig.Name); err != nil {
 			return ret, err
 		}
-		if ret, err := s.tmux.SendKeys(session.Name, windowConfig.StartupScript); err != nil {
+		script, err := s.replacer.Render(windowConfig.StartupScript, session)
+		if err != nil {
+			return "", fmt.Errorf("couldn't render startup script of window %s: %w", window, err)
+		}
+		if ret, err := s.tmux.SendKeys(session.Name, script); err != nil {
 			return ret, err
 		}
 	}
@@ -79,9 +115,8 @@ func (s *RealStartup) Exec(session model.SeshSession) (string, error) {
 	for _, strategy := range strategies {
 		if command, err := strategy(s, session); err != nil {
 			return "", fmt.Errorf("failed to determine startup command: %w", err)
-		} else if command != "" {
-			s.tmux.SendKeys(session.Name, command)
-			return fmt.Sprintf("executing startup command: %s", command), nil
+		} else if len(command) > 0 {
+			return s.run(session, command)
 		}
 	}
 
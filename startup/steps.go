@@ -0,0 +1,139 @@
+package startup
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/model"
+)
+
+// DefaultStepTimeout is how long a step waits for its wait_for pattern
+const DefaultStepTimeout = 10 * time.Second
+
+// pollInterval is how often the pane is captured while waiting
+var pollInterval = 100 * time.Millisecond
+
+// run types the steps of a startup command into the session. Steps that wait
+// for the session to be ready run in the background with `sesh startup-steps`,
+// so connecting to the session isn't blocked by them.
+func (s *RealStartup) run(session model.SeshSession, command model.StartupCommand) (string, error) {
+	rendered := make(model.StartupCommand, 0, len(command))
+	for i, step := range command {
+		keys, err := s.replacer.Render(step.Run, session)
+		if err != nil {
+			return "", fmt.Errorf("startup step %d: %w", i+1, err)
+		}
+		step.Run = keys
+		rendered = append(rendered, step)
+	}
+
+	if !rendered.Waits() {
+		return s.RunSteps(session.Name, rendered)
+	}
+	steps, err := json.Marshal(rendered)
+	if err != nil {
+		return "", fmt.Errorf("couldn't encode startup steps: %w", err)
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("couldn't find the sesh executable: %w", err)
+	}
+	if ret, err := s.tmux.RunShell(session.Name, executable, "startup-steps", session.Name, string(steps)); err != nil {
+		return ret, fmt.Errorf("couldn't start the startup command in the background: %w", err)
+	}
+	return fmt.Sprintf("executing startup command in the background: %s", rendered), nil
+}
+
+// RunBackgroundSteps runs the steps started in the background by run, a
+// failure is shown in the session since nothing else would report it
+func (s *RealStartup) RunBackgroundSteps(name string, command model.StartupCommand) error {
+	if _, err := s.RunSteps(name, command); err != nil {
+		if _, displayErr := s.tmux.DisplayMessage(name, fmt.Sprintf("sesh: %s", err)); displayErr != nil {
+			slog.Warn("Failed to show startup command error", "session", name, "error", displayErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// RunSteps types rendered steps into the session, waiting until the session is
+// ready for each step first
+func (s *RealStartup) RunSteps(name string, command model.StartupCommand) (string, error) {
+	progress := make([]string, 0, len(command))
+	before := "" // the pane before the previous step was sent
+	for i, step := range command {
+		if err := s.wait(name, step, before); err != nil {
+			return strings.Join(progress, "\n"), fmt.Errorf("startup step %d (%s): %w", i+1, step.Run, err)
+		}
+		// The next step only waits for the output of this one
+		before = ""
+		if i+1 < len(command) && command[i+1].WaitFor != "" {
+			before, _ = s.tmux.CapturePaneHistory(name)
+		}
+		if ret, err := s.tmux.SendKeys(name, step.Run); err != nil {
+			return ret, fmt.Errorf("startup step %d (%s): %w", i+1, step.Run, err)
+		}
+		progress = append(progress, fmt.Sprintf("executing startup command: %s", step.Run))
+	}
+	return strings.Join(progress, "\n"), nil
+}
+
+// wait sleeps for the delay of the step and then polls the pane until the
+// output after before matches wait_for
+func (s *RealStartup) wait(name string, step model.StartupStep, before string) error {
+	if step.Delay != "" {
+		delay, err := time.ParseDuration(step.Delay)
+		if err != nil {
+			return fmt.Errorf("invalid delay %q: %w", step.Delay, err)
+		}
+		time.Sleep(delay)
+	}
+
+	if step.WaitFor == "" {
+		return nil
+	}
+	pattern, err := regexp.Compile(step.WaitFor)
+	if err != nil {
+		return fmt.Errorf("invalid wait_for pattern %q: %w", step.WaitFor, err)
+	}
+	timeout := DefaultStepTimeout
+	if step.Timeout != "" {
+		timeout, err = time.ParseDuration(step.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", step.Timeout, err)
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		output, err := s.tmux.CapturePaneHistory(name)
+		if err == nil && pattern.MatchString(newOutput(before, output)) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for %q", timeout, step.WaitFor)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// newOutput returns the lines of the pane that follow the lines it had before.
+// The last line before is left out too, it's the prompt the keys were typed
+// into.
+func newOutput(before, output string) string {
+	before = strings.TrimRight(before, "\n")
+	if before == "" {
+		return output
+	}
+	seen := strings.Count(before, "\n") + 1
+	lines := strings.Split(output, "\n")
+	if len(lines) <= seen {
+		return ""
+	}
+	return strings.Join(lines[seen:], "\n")
+}
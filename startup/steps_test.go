@@ -0,0 +1,159 @@
+package startup
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/replacer"
+	"github.com/joshmedeski/sesh/v2/tmux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRun(t *testing.T) {
+	session := model.SeshSession{Name: "api", Path: "/home/test/c/api"}
+	setup := func() (*RealStartup, *tmux.MockTmux) {
+		mockTmux := new(tmux.MockTmux)
+		mockReplacer := new(replacer.MockReplacer)
+		mockReplacer.On("Render", mock.Anything, session).Return(func(command string, _ model.SeshSession) (string, error) {
+			return command + " " + session.Path, nil
+		})
+		return &RealStartup{tmux: mockTmux, replacer: mockReplacer}, mockTmux
+	}
+
+	t.Run("should send steps that don't wait right away", func(t *testing.T) {
+		s, mockTmux := setup()
+		mockTmux.On("SendKeys", "api", mock.Anything).Return("", nil)
+
+		out, err := s.run(session, model.StartupCommand{{Run: "direnv allow"}, {Run: "nvim"}})
+		assert.Nil(t, err)
+		assert.Equal(t, "executing startup command: direnv allow /home/test/c/api\nexecuting startup command: nvim /home/test/c/api", out)
+		mockTmux.AssertNotCalled(t, "RunShell", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should run steps that wait in the background", func(t *testing.T) {
+		s, mockTmux := setup()
+		mockTmux.On("RunShell", "api", mock.Anything, "startup-steps", "api",
+			`[{"run":"direnv allow /home/test/c/api"},{"run":"nvim /home/test/c/api","wait_for":"direnv: export"}]`).Return("", nil)
+
+		out, err := s.run(session, model.StartupCommand{
+			{Run: "direnv allow"},
+			{Run: "nvim", WaitFor: "direnv: export"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "executing startup command in the background: direnv allow /home/test/c/api; nvim /home/test/c/api", out)
+		mockTmux.AssertExpectations(t)
+		mockTmux.AssertNotCalled(t, "SendKeys", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should pass tmux formats in steps through unchanged", func(t *testing.T) {
+		s, mockTmux := setup()
+		mockTmux.On("RunShell", "api", mock.Anything, "startup-steps", "api",
+			`[{"run":"tmux rename-window #S /home/test/c/api","wait_for":"\\$ $"}]`).Return("", nil)
+
+		_, err := s.run(session, model.StartupCommand{{Run: "tmux rename-window #S", WaitFor: `\$ $`}})
+		assert.Nil(t, err)
+		mockTmux.AssertExpectations(t)
+	})
+}
+
+func TestRunSteps(t *testing.T) {
+	pollInterval = time.Millisecond
+	setup := func() (*RealStartup, *tmux.MockTmux) {
+		mockTmux := new(tmux.MockTmux)
+		return &RealStartup{tmux: mockTmux}, mockTmux
+	}
+
+	t.Run("should wait for the pane output before each step", func(t *testing.T) {
+		s, mockTmux := setup()
+		mockTmux.On("CapturePaneHistory", "api").Return("$", nil).Once() // before sending direnv allow
+		mockTmux.On("CapturePaneHistory", "api").Return("$ direnv allow\nloading...", nil).Twice()
+		mockTmux.On("CapturePaneHistory", "api").Return("$ direnv allow\nloading...\ndirenv: export +AWS_PROFILE", nil)
+		mockTmux.On("SendKeys", "api", mock.Anything).Return("", nil)
+
+		out, err := s.RunSteps("api", model.StartupCommand{
+			{Run: "direnv allow"},
+			{Run: "nvim", WaitFor: "direnv: export"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "executing startup command: direnv allow\nexecuting startup command: nvim", out)
+		mockTmux.AssertNumberOfCalls(t, "CapturePaneHistory", 4)
+		mockTmux.AssertCalled(t, "SendKeys", "api", "nvim")
+	})
+
+	t.Run("should stop when a step times out", func(t *testing.T) {
+		s, mockTmux := setup()
+		mockTmux.On("CapturePaneHistory", "api").Return("", fmt.Errorf("can't find pane"))
+		mockTmux.On("SendKeys", "api", mock.Anything).Return("", nil)
+
+		out, err := s.RunSteps("api", model.StartupCommand{
+			{Run: "direnv allow"},
+			{Run: "nvim", WaitFor: `\$ $`, Timeout: "5ms"},
+			{Run: "git status"},
+		})
+		assert.ErrorContains(t, err, "startup step 2 (nvim): timed out after 5ms")
+		assert.Equal(t, "executing startup command: direnv allow", out)
+		mockTmux.AssertNotCalled(t, "SendKeys", "api", "git status")
+	})
+
+	t.Run("should only match output of the previous step", func(t *testing.T) {
+		s, mockTmux := setup()
+		before := "$ direnv allow\ndirenv: export +AWS_PROFILE\n$ \n\n\n"
+		mockTmux.On("CapturePaneHistory", "api").Return(before, nil).Once()
+		mockTmux.On("CapturePaneHistory", "api").Return("$ direnv allow\ndirenv: export +AWS_PROFILE\n$ cd web\n\n", nil)
+		mockTmux.On("SendKeys", "api", mock.Anything).Return("", nil)
+
+		_, err := s.RunSteps("api", model.StartupCommand{
+			{Run: "cd web"},
+			{Run: "nvim", WaitFor: "direnv: export", Timeout: "5ms"},
+		})
+		assert.ErrorContains(t, err, "startup step 2 (nvim): timed out after 5ms")
+		mockTmux.AssertNotCalled(t, "SendKeys", "api", "nvim")
+	})
+
+	t.Run("should report invalid delays", func(t *testing.T) {
+		s, _ := setup()
+		_, err := s.RunSteps("api", model.StartupCommand{{Run: "nvim", Delay: "soon"}})
+		assert.ErrorContains(t, err, `invalid delay "soon"`)
+	})
+}
+
+func TestRunBackgroundSteps(t *testing.T) {
+	pollInterval = time.Millisecond
+
+	t.Run("should show failures in the session", func(t *testing.T) {
+		mockTmux := new(tmux.MockTmux)
+		mockTmux.On("CapturePaneHistory", "api").Return("$ ", nil)
+		mockTmux.On("DisplayMessage", "api", `sesh: startup step 1 (echo #S): timed out after 5ms waiting for "ready"`).Return("", nil)
+
+		err := (&RealStartup{tmux: mockTmux}).RunBackgroundSteps("api", model.StartupCommand{{Run: "echo #S", WaitFor: "ready", Timeout: "5ms"}})
+		assert.ErrorContains(t, err, "timed out")
+		mockTmux.AssertExpectations(t)
+		mockTmux.AssertNotCalled(t, "SendKeys", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should not show anything when the steps succeed", func(t *testing.T) {
+		mockTmux := new(tmux.MockTmux)
+		mockTmux.On("SendKeys", "api", "echo #S").Return("", nil)
+
+		err := (&RealStartup{tmux: mockTmux}).RunBackgroundSteps("api", model.StartupCommand{{Run: "echo #S"}})
+		assert.Nil(t, err)
+		mockTmux.AssertNotCalled(t, "DisplayMessage", mock.Anything, mock.Anything)
+	})
+}
+
+func TestNewOutput(t *testing.T) {
+	t.Run("should return the whole pane without earlier output", func(t *testing.T) {
+		assert.Equal(t, "$ nvim", newOutput("", "$ nvim"))
+	})
+
+	t.Run("should skip the lines that were there before", func(t *testing.T) {
+		assert.Equal(t, "ready\n$ ", newOutput("old\n$ \n\n", "old\n$ make\nready\n$ "))
+	})
+
+	t.Run("should return nothing when there's no new line", func(t *testing.T) {
+		assert.Equal(t, "", newOutput("old\n$ ", "old\n$ make"))
+	})
+}
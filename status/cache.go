@@ -0,0 +1,41 @@
+package status
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/cache"
+)
+
+// CacheSource is the source of status lines in the sesh cache
+const CacheSource = "status"
+
+type Cache interface {
+	Get(key string) (string, bool)
+	Set(key string, output string, ttl time.Duration)
+}
+
+type RealCache struct {
+	store cache.Cache
+}
+
+func NewCache(store cache.Cache) Cache {
+	return &RealCache{store: store}
+}
+
+func (c *RealCache) Get(key string) (string, bool) {
+	var output string
+	entry, found := c.store.Get(CacheSource, key, &output)
+	if !found || entry.Expired() {
+		return "", false
+	}
+	return output, true
+}
+
+func (c *RealCache) Set(key string, output string, ttl time.Duration) {
+	// Several tmux clients may refresh their status line at the same time,
+	// the store replaces the file atomically
+	if err := c.store.Set(CacheSource, key, output, ttl); err != nil {
+		slog.Error("Failed to write status cache", "error", err)
+	}
+}
@@ -0,0 +1,159 @@
+package status
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/icon"
+	"github.com/joshmedeski/sesh/v2/lister"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/tmux"
+)
+
+const (
+	DefaultFormat   = "{{.Icon}} {{.Name}}{{with .Branch}}  {{.}}{{end}}{{with .Alerts}} !{{.}}{{end}}{{with .Activity}} +{{.}}{{end}}"
+	DefaultCacheTTL = 5 // in seconds
+)
+
+type Status interface {
+	// Renders the status line for the given session (or the attached one when empty)
+	Render(name string, format string) (string, error)
+}
+
+// Data is passed to the status format template
+type Data struct {
+	Icon     string // The icon of the session source
+	Src      string // The source of the session (config, tmux)
+	Name     string // The name of the session
+	Path     string // The path of the session
+	Branch   string // The git branch of the session path
+	Windows  int    // The number of windows in the session
+	Attached int    // The number of clients attached to the session
+	Alerts   int    // The number of other sessions with alerts
+	Activity int    // The number of other sessions with activity since they were last attached
+}
+
+type RealStatus struct {
+	config model.Config
+	tmux   tmux.Tmux
+	git    git.Git
+	icon   icon.Icon
+	lister lister.Lister
+	cache  Cache
+}
+
+func NewStatus(config model.Config, tmux tmux.Tmux, git git.Git, icon icon.Icon, lister lister.Lister, cache Cache) Status {
+	return &RealStatus{config, tmux, git, icon, lister, cache}
+}
+
+func (s *RealStatus) Render(name string, format string) (string, error) {
+	if format == "" {
+		format = s.config.Status.Format
+	}
+	if format == "" {
+		format = DefaultFormat
+	}
+
+	// Without a name the session depends on the client, so it isn't cached
+	key := cacheKey(name, format)
+	if name != "" {
+		if output, found := s.cache.Get(key); found {
+			return output, nil
+		}
+	}
+
+	tmpl, err := template.New("status").Parse(format)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse status format: %w", err)
+	}
+
+	sessions, err := s.tmux.ListSessions()
+	if err != nil {
+		return "", fmt.Errorf("couldn't list tmux sessions: %w", err)
+	}
+
+	current, found := currentSession(sessions, name)
+	if !found {
+		return "", nil
+	}
+
+	data := s.data(current, sessions)
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("couldn't render status format: %w", err)
+	}
+
+	if name != "" {
+		ttl := s.config.Status.CacheTTL
+		if ttl == 0 {
+			ttl = DefaultCacheTTL
+		}
+		s.cache.Set(key, out.String(), time.Duration(ttl)*time.Second)
+	}
+
+	return out.String(), nil
+}
+
+func (s *RealStatus) data(current *model.TmuxSession, sessions []*model.TmuxSession) Data {
+	src := "tmux"
+	if _, exists := s.lister.FindConfigSession(current.Name); exists {
+		src = "config"
+	}
+
+	branch, err := s.git.CurrentBranch(current.Path)
+	if err != nil {
+		branch = ""
+	}
+
+	data := Data{
+		Icon:     s.icon.SrcIcon(src),
+		Src:      src,
+		Name:     current.Name,
+		Path:     current.Path,
+		Branch:   branch,
+		Windows:  current.Windows,
+		Attached: current.Attached,
+	}
+
+	for _, session := range sessions {
+		if session.Name == current.Name {
+			continue
+		}
+		if len(session.Alerts) > 0 {
+			data.Alerts++
+		} else if hasActivity(session) {
+			data.Activity++
+		}
+	}
+
+	return data
+}
+
+// currentSession finds the session by name, falling back to the first attached session
+func currentSession(sessions []*model.TmuxSession, name string) (*model.TmuxSession, bool) {
+	for _, session := range sessions {
+		if name != "" && session.Name == name {
+			return session, true
+		}
+		if name == "" && session.Attached > 0 {
+			return session, true
+		}
+	}
+	return nil, false
+}
+
+// hasActivity reports whether a detached session had activity since it was last attached
+func hasActivity(session *model.TmuxSession) bool {
+	if session.Attached > 0 || session.Activity == nil || session.LastAttached == nil {
+		return false
+	}
+	return session.Activity.After(*session.LastAttached)
+}
+
+func cacheKey(name, format string) string {
+	return fmt.Sprintf("%s|%s", name, format)
+}
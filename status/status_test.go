@@ -0,0 +1,76 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joshmedeski/sesh/v2/git"
+	"github.com/joshmedeski/sesh/v2/icon"
+	"github.com/joshmedeski/sesh/v2/lister"
+	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/tmux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRender(t *testing.T) {
+	lastAttached := time.Unix(1714089765, 0)
+	before := lastAttached.Add(-time.Minute)
+	after := lastAttached.Add(time.Minute)
+	sessions := []*model.TmuxSession{
+		{Name: "sesh", Path: "/Users/josh/c/sesh", Attached: 1, Windows: 2, Activity: &after, LastAttached: &lastAttached},
+		{Name: "dotfiles", Path: "/Users/josh/c/dotfiles", Alerts: []int{1}, Activity: &after, LastAttached: &lastAttached},
+		{Name: "blog", Path: "/Users/josh/c/blog", Activity: &after, LastAttached: &lastAttached},
+		{Name: "notes", Path: "/Users/josh/notes", Activity: &before, LastAttached: &lastAttached},
+	}
+
+	newStatus := func() (*RealStatus, *MockCache) {
+		mockTmux := new(tmux.MockTmux)
+		mockGit := new(git.MockGit)
+		mockIcon := new(icon.MockIcon)
+		mockLister := new(lister.MockLister)
+		mockCache := new(MockCache)
+		mockTmux.On("ListSessions").Return(sessions, nil)
+		mockGit.On("CurrentBranch", "/Users/josh/c/sesh").Return("main", nil)
+		mockIcon.On("SrcIcon", "tmux").Return("T")
+		mockLister.On("FindConfigSession", "sesh").Return(model.SeshSession{}, false)
+		mockCache.On("Get", mock.Anything).Return("", false)
+		mockCache.On("Set", mock.Anything, mock.Anything, 5*time.Second).Return()
+		s := &RealStatus{model.Config{}, mockTmux, mockGit, mockIcon, mockLister, mockCache}
+		return s, mockCache
+	}
+
+	t.Run("should render the attached session with the default format", func(t *testing.T) {
+		s, mockCache := newStatus()
+		output, err := s.Render("", "")
+		assert.Nil(t, err)
+		assert.Equal(t, "T sesh  main !1 +1", output)
+		// the attached session depends on the client
+		mockCache.AssertNotCalled(t, "Get", mock.Anything)
+		mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should render the named session with a custom format", func(t *testing.T) {
+		s, mockCache := newStatus()
+		output, err := s.Render("sesh", "{{.Name}}:{{.Windows}}")
+		assert.Nil(t, err)
+		assert.Equal(t, "sesh:2", output)
+		mockCache.AssertCalled(t, "Set", "sesh|{{.Name}}:{{.Windows}}", "sesh:2", 5*time.Second)
+	})
+
+	t.Run("should return the cached output", func(t *testing.T) {
+		mockCache := new(MockCache)
+		mockCache.On("Get", "sesh|{{.Name}}").Return("cached", true)
+		s := &RealStatus{model.Config{}, nil, nil, nil, nil, mockCache}
+		output, err := s.Render("sesh", "{{.Name}}")
+		assert.Nil(t, err)
+		assert.Equal(t, "cached", output)
+	})
+
+	t.Run("should render nothing for an unknown session", func(t *testing.T) {
+		s, _ := newStatus()
+		output, err := s.Render("missing", "")
+		assert.Nil(t, err)
+		assert.Equal(t, "", output)
+	})
+}
@@ -1,6 +1,10 @@
 package tmux
 
 import (
+	"maps"
+	"slices"
+	"strings"
+
 	"github.com/joshmedeski/sesh/v2/model"
 	"github.com/joshmedeski/sesh/v2/oswrap"
 	"github.com/joshmedeski/sesh/v2/shell"
@@ -8,15 +12,18 @@ import (
 
 type Tmux interface {
 	ListSessions() ([]*model.TmuxSession, error)
-	NewSession(sessionName string, startDir string) (string, error)
+	NewSession(sessionName string, startDir string, env map[string]string) (string, error)
 	NewWindow(startDir string, name string) (string, error)
 	IsAttached() bool
 	AttachSession(targetSession string) (string, error)
 	SendKeys(name string, command string) (string, error)
 	SwitchClient(targetSession string) (string, error)
 	CapturePane(targetSession string) (string, error)
+	CapturePaneHistory(targetSession string) (string, error)
 	NextWindow() (string, error)
 	SwitchOrAttach(name string, opts model.ConnectOpts) (string, error)
+	RunShell(targetSession string, command ...string) (string, error)
+	DisplayMessage(targetSession string, message string) (string, error)
 }
 
 type RealTmux struct {
@@ -40,8 +47,14 @@ func (t *RealTmux) SendKeys(targetPane string, keys string) (string, error) {
 	return t.shell.Cmd("tmux", "send-keys", "-t", targetPane, keys, "Enter")
 }
 
-func (t *RealTmux) NewSession(sessionName string, startDir string) (string, error) {
-	return t.shell.Cmd("tmux", "new-session", "-d", "-s", sessionName, "-c", startDir)
+// NewSession creates a detached session, the environment is set before the
+// first pane starts so it inherits it too
+func (t *RealTmux) NewSession(sessionName string, startDir string, env map[string]string) (string, error) {
+	args := []string{"new-session", "-d", "-s", sessionName, "-c", startDir}
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		args = append(args, "-e", name+"="+env[name])
+	}
+	return t.shell.Cmd("tmux", args...)
 }
 
 func (t *RealTmux) NewWindow(startDir string, name string) (string, error) {
@@ -52,10 +65,37 @@ func (t *RealTmux) CapturePane(targetSession string) (string, error) {
 	return t.shell.Cmd("tmux", "capture-pane", "-e", "-p", "-t", targetSession)
 }
 
+// CapturePaneHistory captures the scrollback of the pane too, without escape
+// sequences and with wrapped lines joined, so earlier output keeps its line
+func (t *RealTmux) CapturePaneHistory(targetSession string) (string, error) {
+	return t.shell.Cmd("tmux", "capture-pane", "-p", "-J", "-S", "-", "-t", targetSession)
+}
+
 func (t *RealTmux) NextWindow() (string, error) {
 	return t.shell.Cmd("tmux", "next-window")
 }
 
+// RunShell runs a command in the background of the tmux server, its output is
+// shown in the session
+func (t *RealTmux) RunShell(targetSession string, command ...string) (string, error) {
+	quoted := make([]string, 0, len(command))
+	for _, arg := range command {
+		quoted = append(quoted, shell.Quote(arg))
+	}
+	return t.shell.Cmd("tmux", "run-shell", "-b", "-t", targetSession, escapeFormat(strings.Join(quoted, " ")))
+}
+
+// DisplayMessage shows a message in the status line of the clients of the session
+func (t *RealTmux) DisplayMessage(targetSession string, message string) (string, error) {
+	return t.shell.Cmd("tmux", "display-message", "-t", targetSession, escapeFormat(message))
+}
+
+// escapeFormat keeps tmux from expanding formats like #{...} and #S in a
+// string it would otherwise expand
+func escapeFormat(s string) string {
+	return strings.ReplaceAll(s, "#", "##")
+}
+
 func (t *RealTmux) IsAttached() bool {
 	return len(t.os.Getenv("TMUX")) > 0
 }
@@ -0,0 +1,61 @@
+package tmux
+
+import (
+	"testing"
+
+	"github.com/joshmedeski/sesh/v2/oswrap"
+	"github.com/joshmedeski/sesh/v2/shell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSession(t *testing.T) {
+	mockShell := new(shell.MockShell)
+	tmux := NewTmux(new(oswrap.MockOs), mockShell)
+
+	t.Run("creates a detached session", func(t *testing.T) {
+		mockShell.ExpectedCalls = nil
+		mockShell.On("Cmd", "tmux", "new-session", "-d", "-s", "dotfiles", "-c", "/home/user/dotfiles").Return("", nil)
+		_, err := tmux.NewSession("dotfiles", "/home/user/dotfiles", nil)
+		assert.Nil(t, err)
+		mockShell.AssertExpectations(t)
+	})
+
+	t.Run("sets the environment of the first pane", func(t *testing.T) {
+		mockShell.ExpectedCalls = nil
+		mockShell.On("Cmd", "tmux", "new-session", "-d", "-s", "api", "-c", "/home/user/c/api",
+			"-e", "AWS_PROFILE=acme", "-e", "NODE_ENV=development").Return("", nil)
+		_, err := tmux.NewSession("api", "/home/user/c/api", map[string]string{
+			"NODE_ENV":    "development",
+			"AWS_PROFILE": "acme",
+		})
+		assert.Nil(t, err)
+		mockShell.AssertExpectations(t)
+	})
+}
+
+func TestRunShell(t *testing.T) {
+	mockShell := new(shell.MockShell)
+	tmux := NewTmux(new(oswrap.MockOs), mockShell)
+
+	t.Run("quotes the command for the shell", func(t *testing.T) {
+		mockShell.On("Cmd", "tmux", "run-shell", "-b", "-t", "api", `'/usr/bin/sesh' 'startup-steps' 'api' '[{"run":"echo '\''hi'\''"}]'`).Return("", nil)
+		_, err := tmux.RunShell("api", "/usr/bin/sesh", "startup-steps", "api", `[{"run":"echo 'hi'"}]`)
+		assert.Nil(t, err)
+		mockShell.AssertExpectations(t)
+	})
+
+	t.Run("escapes tmux formats", func(t *testing.T) {
+		mockShell.On("Cmd", "tmux", "run-shell", "-b", "-t", "api", `'/usr/bin/sesh' 'startup-steps' 'api' '[{"run":"echo ##S ##{pane_id} ####"}]'`).Return("", nil)
+		_, err := tmux.RunShell("api", "/usr/bin/sesh", "startup-steps", "api", `[{"run":"echo #S #{pane_id} ##"}]`)
+		assert.Nil(t, err)
+		mockShell.AssertExpectations(t)
+	})
+}
+
+func TestDisplayMessage(t *testing.T) {
+	mockShell := new(shell.MockShell)
+	mockShell.On("Cmd", "tmux", "display-message", "-t", "api", "sesh: step 1 (echo ##S) failed").Return("", nil)
+	_, err := NewTmux(new(oswrap.MockOs), mockShell).DisplayMessage("api", "sesh: step 1 (echo #S) failed")
+	assert.Nil(t, err)
+	mockShell.AssertExpectations(t)
+}
@@ -1,18 +1,85 @@
 package tmuxinator
 
 import (
+	"log/slog"
+	"os"
+	"path/filepath"
 	"slices"
+	"time"
 
 	"github.com/joshmedeski/sesh/v2/model"
 )
 
+// CacheSource is the source of tmuxinator projects in the sesh cache
+const CacheSource = "tmuxinator"
+
+// cacheTTL bounds how long projects are cached, they're listed again as soon
+// as a project file or a tmuxinator config directory changes
+const cacheTTL = 24 * time.Hour
+
+type cachedProjects struct {
+	Projects []*model.TmuxinatorConfig `json:"projects"`
+	ModTime  time.Time                 `json:"mod_time"`
+}
+
 func (t *RealTmuxinator) List() ([]*model.TmuxinatorConfig, error) {
+	// `tmuxinator list` starts ruby, so the projects are cached until a
+	// project is added, removed or edited
+	modTime := t.configModTime()
+	var cached cachedProjects
+	if entry, found := t.cache.Get(CacheSource, "projects", &cached); found && !entry.Expired() && cached.ModTime.Equal(modTime) {
+		return cached.Projects, nil
+	}
+
 	res, err := t.shell.ListCmd("tmuxinator", "list", "-n")
 	if err != nil {
 		// NOTE: return empty list if error
 		return []*model.TmuxinatorConfig{}, nil
 	}
-	return parseTmuxinatorConfigsOutput(res)
+	projects, err := parseTmuxinatorConfigsOutput(res)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.cache.Set(CacheSource, "projects", cachedProjects{Projects: projects, ModTime: modTime}, cacheTTL); err != nil {
+		slog.Warn("Failed to cache tmuxinator projects", "error", err)
+	}
+	return projects, nil
+}
+
+// configModTime is the latest modification of the directories tmuxinator
+// looks for projects in and of the project files in them
+func (t *RealTmuxinator) configModTime() time.Time {
+	var dirs []string
+	if dir := t.os.Getenv("TMUXINATOR_CONFIG"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if dir := t.os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		dirs = append(dirs, filepath.Join(dir, "tmuxinator"))
+	}
+	for _, dir := range []string{"~/.config/tmuxinator", "~/.tmuxinator"} {
+		if expanded, err := t.home.ExpandHome(dir); err == nil {
+			dirs = append(dirs, filepath.FromSlash(expanded))
+		}
+	}
+
+	var latest time.Time
+	update := func(info os.FileInfo, err error) {
+		if err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	for _, dir := range dirs {
+		update(t.os.Stat(dir))
+		entries, err := t.os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			update(entry.Info())
+		}
+	}
+	return latest
 }
 
 func parseTmuxinatorConfigsOutput(rawList []string) ([]*model.TmuxinatorConfig, error) {
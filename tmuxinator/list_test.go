@@ -1,17 +1,41 @@
 package tmuxinator
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/joshmedeski/sesh/v2/cache"
+	"github.com/joshmedeski/sesh/v2/home"
 	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
 	"github.com/joshmedeski/sesh/v2/shell"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+// homeAt expands ~ to the directory
+func homeAt(dir string) *home.MockHome {
+	mockHome := new(home.MockHome)
+	mockHome.On("ExpandHome", mock.Anything).Return(func(path string) (string, error) {
+		return strings.Replace(path, "~", dir, 1), nil
+	})
+	return mockHome
+}
+
 func TestListConfigs(t *testing.T) {
 	t.Run("List Tmuxinator Configs", func(t *testing.T) {
+		mockOs := new(oswrap.MockOs)
+		mockOs.On("Getenv", mock.Anything).Return("")
+		mockOs.On("Stat", mock.Anything).Return(nil, os.ErrNotExist)
+		mockOs.On("ReadDir", mock.Anything).Return(nil, os.ErrNotExist)
 		mockShell := new(shell.MockShell)
-		tmuxinator := &RealTmuxinator{shell: mockShell}
+		mockCache := new(cache.MockCache)
+		tmuxinator := &RealTmuxinator{os: mockOs, home: homeAt("/home/test"), shell: mockShell, cache: mockCache}
+		mockCache.EXPECT().Get(CacheSource, "projects", mock.Anything).Return(cache.Entry{}, false)
+		mockCache.EXPECT().Set(CacheSource, "projects", mock.Anything, cacheTTL).Return(nil)
 		mockShell.EXPECT().ListCmd("tmuxinator", "list", "-n").Return([]string{
 			"tmuxinator projects:",
 			"dotfiles",
@@ -29,3 +53,22 @@ func TestListConfigs(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	})
 }
+
+func TestConfigModTime(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("TMUXINATOR_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	configDir := filepath.Join(homeDir, ".config", "tmuxinator")
+	project := filepath.Join(configDir, "sesh.yml")
+	assert.Nil(t, os.MkdirAll(configDir, 0755))
+	assert.Nil(t, os.WriteFile(project, []byte("name: sesh"), 0644))
+	tmuxinator := &RealTmuxinator{os: oswrap.NewOs(), home: homeAt(homeDir)}
+	before := tmuxinator.configModTime()
+	assert.False(t, before.IsZero())
+
+	t.Run("should change when a project is edited", func(t *testing.T) {
+		edited := before.Add(time.Minute)
+		assert.Nil(t, os.Chtimes(project, edited, edited))
+		assert.True(t, edited.Equal(tmuxinator.configModTime()))
+	})
+}
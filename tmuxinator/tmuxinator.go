@@ -1,7 +1,10 @@
 package tmuxinator
 
 import (
+	"github.com/joshmedeski/sesh/v2/cache"
+	"github.com/joshmedeski/sesh/v2/home"
 	"github.com/joshmedeski/sesh/v2/model"
+	"github.com/joshmedeski/sesh/v2/oswrap"
 	"github.com/joshmedeski/sesh/v2/shell"
 )
 
@@ -11,11 +14,14 @@ type Tmuxinator interface {
 }
 
 type RealTmuxinator struct {
+	os    oswrap.Os
+	home  home.Home
 	shell shell.Shell
+	cache cache.Cache
 }
 
-func NewTmuxinator(shell shell.Shell) Tmuxinator {
-	return &RealTmuxinator{shell}
+func NewTmuxinator(os oswrap.Os, home home.Home, shell shell.Shell, cache cache.Cache) Tmuxinator {
+	return &RealTmuxinator{os, home, shell, cache}
 }
 
 func (t *RealTmuxinator) Start(targetSession string) (string, error) {